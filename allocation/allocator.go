@@ -0,0 +1,144 @@
+package allocation
+
+import (
+	"fmt"
+
+	"overtime_go/core"
+)
+
+// DefaultMaxHoursPerEmployee سقف ساعت اضافه کاری هر نفر است (همان محدوده 0 تا 999 جدول).
+const DefaultMaxHoursPerEmployee = 999
+
+// Constraints محدودیت‌های عمومی موتور تخصیص را نگه می‌دارد.
+type Constraints struct {
+	MaxHoursPerEmployee int // صفر یعنی بدون سقف
+}
+
+// DefaultConstraints محدودیت‌های پیش‌فرض برنامه را برمی‌گرداند.
+func DefaultConstraints() Constraints {
+	return Constraints{
+		MaxHoursPerEmployee: DefaultMaxHoursPerEmployee,
+	}
+}
+
+// Severity سطح اهمیت یک پیام تشخیصی است.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "هشدار"
+	case SeverityError:
+		return "خطا"
+	}
+	return "اطلاع"
+}
+
+// Diagnostic یک پیام تشخیصی حاصل از تخصیص است. EmployeeID برای پیام‌های کلی واحد خالی است.
+type Diagnostic struct {
+	Severity   Severity
+	EmployeeID string
+	Message    string
+}
+
+func (d Diagnostic) String() string {
+	if d.EmployeeID != "" {
+		return fmt.Sprintf("%s (%s): %s", d.Severity, d.EmployeeID, d.Message)
+	}
+	return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+}
+
+// Result خروجی موتور تخصیص است. Employees یک کپی جدید است و داده ورودی تغییر نمی‌کند.
+type Result struct {
+	Employees   []core.Employee
+	Diagnostics []Diagnostic
+}
+
+// AllocatedHours مجموع ساعات تخصیص یافته در نتیجه را برمی‌گرداند.
+func (r Result) AllocatedHours() int {
+	total := 0
+	for _, emp := range r.Employees {
+		total += emp.Hours
+	}
+	return total
+}
+
+// HasErrors مشخص می‌کند که آیا نتیجه پیام تشخیصی سطح خطا دارد یا نه.
+func (r Result) HasErrors() bool {
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Result) addDiagnostic(severity Severity, employeeID, format string, args ...interface{}) {
+	r.Diagnostics = append(r.Diagnostics, Diagnostic{
+		Severity:   severity,
+		EmployeeID: employeeID,
+		Message:    fmt.Sprintf(format, args...),
+	})
+}
+
+// Allocate سرانه واحد (TotalHours) را پس از کسر ساعات پرسنل قفل شده بر اساس روش توزیع
+// واحد (data.Strategy) بین پرسنل قفل نشده تقسیم می‌کند. حداقل و سقف ساعات هر نفر رعایت شده و
+// اگر سرانه در این محدوده‌ها قابل تأمین نباشد، پیام تشخیصی سطح خطا برگردانده می‌شود.
+func Allocate(data core.DepartmentData, constraints Constraints) Result {
+	result := Result{
+		Employees: make([]core.Employee, len(data.Employees)),
+	}
+	copy(result.Employees, data.Employees)
+
+	if len(result.Employees) == 0 {
+		if data.TotalHours > 0 {
+			result.addDiagnostic(SeverityWarning, "", "واحد '%s' پرسنلی برای توزیع %d ساعت ندارد.", data.DepartmentShiftName, data.TotalHours)
+		}
+		return result
+	}
+
+	lockedSum := 0
+	var unlockedIndices []int
+	var limits []bounds
+	for i, emp := range result.Employees {
+		min, max := EffectiveLimits(emp, data, constraints)
+		if max != Unlimited && min > max {
+			result.addDiagnostic(SeverityError, emp.ID, "حداقل ساعت '%s' (%d) از سقف آن (%d) بیشتر است؛ سقف ملاک قرار گرفت.", emp.Name, min, max)
+			min = max
+		}
+		if emp.Locked {
+			lockedSum += emp.Hours
+			if b := (bounds{min, max}); b.clamp(emp.Hours) != emp.Hours {
+				result.addDiagnostic(SeverityWarning, emp.ID, "ساعت قفل شده '%s' (%d) خارج از محدوده مجاز است.", emp.Name, emp.Hours)
+			}
+		} else {
+			unlockedIndices = append(unlockedIndices, i)
+			limits = append(limits, bounds{min, max})
+		}
+	}
+
+	remainingTotalHours := data.TotalHours - lockedSum
+	if remainingTotalHours < 0 {
+		result.addDiagnostic(SeverityError, "", "مجموع ساعات پرسنل قفل شده (%d) از سرانه (%d) بیشتر است.", lockedSum, data.TotalHours)
+		remainingTotalHours = 0
+	}
+
+	if len(unlockedIndices) == 0 {
+		if remainingTotalHours > 0 {
+			result.addDiagnostic(SeverityWarning, "", "تمام پرسنل قفل هستند اما هنوز %d ساعت برای توزیع باقی مانده است.", remainingTotalHours)
+		}
+		return result
+	}
+
+	shares := allocateWithinLimits(&result, data, unlockedIndices, remainingTotalHours, limits)
+	for n, idx := range unlockedIndices {
+		result.Employees[idx].Hours = shares[n]
+	}
+	return result
+}
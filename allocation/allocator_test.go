@@ -0,0 +1,233 @@
+package allocation
+
+import (
+	"reflect"
+	"testing"
+
+	"overtime_go/core"
+)
+
+// employees پرسنل آزمون را با کد پرسنلی ترتیبی می‌سازد.
+func employees(n int) []core.Employee {
+	list := make([]core.Employee, n)
+	for i := range list {
+		list[i] = core.Employee{Name: string(rune('A' + i)), ID: string(rune('1' + i))}
+	}
+	return list
+}
+
+func withEmployees(n int, edit func([]core.Employee)) []core.Employee {
+	list := employees(n)
+	edit(list)
+	return list
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           core.DepartmentData
+		wantHours      []int
+		wantSeverities []Severity
+	}{
+		{
+			name:      "equal split gives remainder to first employees",
+			data:      core.DepartmentData{TotalHours: 100, Employees: employees(3)},
+			wantHours: []int{34, 33, 33},
+		},
+		{
+			name:      "explicit equal strategy",
+			data:      core.DepartmentData{TotalHours: 90, Strategy: core.StrategyEqual, Employees: employees(3)},
+			wantHours: []int{30, 30, 30},
+		},
+		{
+			name: "weighted",
+			data: core.DepartmentData{TotalHours: 100, Strategy: core.StrategyWeighted, Employees: withEmployees(2, func(e []core.Employee) {
+				e[0].Weight, e[1].Weight = 1, 3
+			})},
+			wantHours: []int{25, 75},
+		},
+		{
+			name:           "weighted without weights falls back to equal",
+			data:           core.DepartmentData{TotalHours: 10, Strategy: core.StrategyWeighted, Employees: employees(2)},
+			wantHours:      []int{5, 5},
+			wantSeverities: []Severity{SeverityWarning},
+		},
+		{
+			name: "production days",
+			data: core.DepartmentData{TotalHours: 80, Strategy: core.StrategyProductionDays, Employees: withEmployees(2, func(e []core.Employee) {
+				e[0].ProductionDays, e[1].ProductionDays = 10, 30
+			})},
+			wantHours: []int{20, 60},
+		},
+		{
+			name:           "production days without days falls back to equal",
+			data:           core.DepartmentData{TotalHours: 9, Strategy: core.StrategyProductionDays, Employees: employees(3)},
+			wantHours:      []int{3, 3, 3},
+			wantSeverities: []Severity{SeverityWarning},
+		},
+		{
+			name: "seniority gives remainder to most senior",
+			data: core.DepartmentData{TotalHours: 11, Strategy: core.StrategySeniority, Employees: withEmployees(3, func(e []core.Employee) {
+				e[0].Seniority, e[1].Seniority, e[2].Seniority = 1, 5, 3
+			})},
+			wantHours: []int{3, 4, 4},
+		},
+		{
+			name:      "fill to cap",
+			data:      core.DepartmentData{TotalHours: 100, Strategy: core.StrategyFillToCap, FillCapHours: 40, Employees: employees(3)},
+			wantHours: []int{40, 40, 20},
+		},
+		{
+			name:           "fill to cap leaves budget undistributed",
+			data:           core.DepartmentData{TotalHours: 100, Strategy: core.StrategyFillToCap, FillCapHours: 20, Employees: employees(3)},
+			wantHours:      []int{20, 20, 20},
+			wantSeverities: []Severity{SeverityWarning},
+		},
+		{
+			name:           "fill to cap without cap falls back to equal",
+			data:           core.DepartmentData{TotalHours: 30, Strategy: core.StrategyFillToCap, Employees: employees(3)},
+			wantHours:      []int{10, 10, 10},
+			wantSeverities: []Severity{SeverityError},
+		},
+		{
+			name: "locked hours are subtracted from the budget",
+			data: core.DepartmentData{TotalHours: 100, Employees: withEmployees(3, func(e []core.Employee) {
+				e[0].Hours, e[0].Locked = 30, true
+			})},
+			wantHours: []int{30, 35, 35},
+		},
+		{
+			name: "locked hours exceeding the budget",
+			data: core.DepartmentData{TotalHours: 50, Employees: withEmployees(3, func(e []core.Employee) {
+				e[0].Hours, e[0].Locked = 80, true
+				e[1].Hours = 12
+			})},
+			wantHours:      []int{80, 0, 0},
+			wantSeverities: []Severity{SeverityError},
+		},
+		{
+			name: "locked hours outside the employee limits",
+			data: core.DepartmentData{TotalHours: 60, Employees: withEmployees(2, func(e []core.Employee) {
+				e[0].Hours, e[0].Locked, e[0].MaxHours = 50, true, 40
+			})},
+			wantHours:      []int{50, 10},
+			wantSeverities: []Severity{SeverityWarning},
+		},
+		{
+			name: "all employees locked with budget left",
+			data: core.DepartmentData{TotalHours: 100, Employees: withEmployees(2, func(e []core.Employee) {
+				e[0].Hours, e[0].Locked = 30, true
+				e[1].Hours, e[1].Locked = 40, true
+			})},
+			wantHours:      []int{30, 40},
+			wantSeverities: []Severity{SeverityWarning},
+		},
+		{
+			name: "all employees locked matching the budget",
+			data: core.DepartmentData{TotalHours: 70, Employees: withEmployees(2, func(e []core.Employee) {
+				e[0].Hours, e[0].Locked = 30, true
+				e[1].Hours, e[1].Locked = 40, true
+			})},
+			wantHours: []int{30, 40},
+		},
+		{
+			name: "employee maximum is redistributed to others",
+			data: core.DepartmentData{TotalHours: 100, Employees: withEmployees(3, func(e []core.Employee) {
+				e[0].MaxHours = 10
+			})},
+			wantHours: []int{10, 45, 45},
+		},
+		{
+			name: "employee minimum is taken from others",
+			data: core.DepartmentData{TotalHours: 90, Employees: withEmployees(3, func(e []core.Employee) {
+				e[0].MinHours = 50
+			})},
+			wantHours: []int{50, 20, 20},
+		},
+		{
+			name:           "department minimums exceed the budget",
+			data:           core.DepartmentData{TotalHours: 100, DefaultMinHours: 40, Employees: employees(3)},
+			wantHours:      []int{40, 40, 40},
+			wantSeverities: []Severity{SeverityError},
+		},
+		{
+			name:           "department maximums cannot cover the budget",
+			data:           core.DepartmentData{TotalHours: 100, DefaultMaxHours: 20, Employees: employees(3)},
+			wantHours:      []int{20, 20, 20},
+			wantSeverities: []Severity{SeverityError},
+		},
+		{
+			name: "minimum above maximum uses the maximum",
+			data: core.DepartmentData{TotalHours: 50, Employees: withEmployees(2, func(e []core.Employee) {
+				e[0].MinHours, e[0].MaxHours = 30, 20
+			})},
+			wantHours:      []int{20, 30},
+			wantSeverities: []Severity{SeverityError},
+		},
+		{
+			name: "zero budget",
+			data: core.DepartmentData{TotalHours: 0, Employees: withEmployees(2, func(e []core.Employee) {
+				e[0].Hours, e[1].Hours = 5, 7
+			})},
+			wantHours: []int{0, 0},
+		},
+		{
+			name:           "negative budget",
+			data:           core.DepartmentData{TotalHours: -10, Employees: employees(2)},
+			wantHours:      []int{0, 0},
+			wantSeverities: []Severity{SeverityError},
+		},
+		{
+			name:           "budget without employees",
+			data:           core.DepartmentData{TotalHours: 10},
+			wantHours:      []int{},
+			wantSeverities: []Severity{SeverityWarning},
+		},
+		{
+			name:           "unknown strategy falls back to equal",
+			data:           core.DepartmentData{TotalHours: 4, Strategy: "random", Employees: employees(2)},
+			wantHours:      []int{2, 2},
+			wantSeverities: []Severity{SeverityWarning},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := append([]core.Employee(nil), tt.data.Employees...)
+
+			result := Allocate(tt.data, DefaultConstraints())
+
+			gotHours := make([]int, len(result.Employees))
+			for i, emp := range result.Employees {
+				gotHours[i] = emp.Hours
+			}
+			if !reflect.DeepEqual(gotHours, tt.wantHours) {
+				t.Errorf("hours = %v, want %v", gotHours, tt.wantHours)
+			}
+
+			var gotSeverities []Severity
+			for _, d := range result.Diagnostics {
+				gotSeverities = append(gotSeverities, d.Severity)
+			}
+			if !reflect.DeepEqual(gotSeverities, tt.wantSeverities) {
+				t.Errorf("diagnostics = %v, want severities %v", result.Diagnostics, tt.wantSeverities)
+			}
+			if result.HasErrors() != containsSeverity(tt.wantSeverities, SeverityError) {
+				t.Errorf("HasErrors() = %v", result.HasErrors())
+			}
+
+			if !reflect.DeepEqual(tt.data.Employees, original) {
+				t.Errorf("Allocate modified its input: %v, want %v", tt.data.Employees, original)
+			}
+		})
+	}
+}
+
+func containsSeverity(list []Severity, severity Severity) bool {
+	for _, s := range list {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package allocation
+
+import (
+	"overtime_go/core"
+)
+
+// Unlimited مقدار سقف برای کارمندی است که هیچ محدودیتی ندارد.
+const Unlimited = -1
+
+// EffectiveLimits حداقل و حداکثر ساعت مجاز یک نفر را با در نظر گرفتن مقادیر فردی،
+// پیش‌فرض‌های واحد و محدودیت‌های عمومی برمی‌گرداند. اگر سقفی وجود نداشته باشد max برابر Unlimited است.
+func EffectiveLimits(emp core.Employee, data core.DepartmentData, constraints Constraints) (min, max int) {
+	min = emp.MinHours
+	if min <= 0 {
+		min = data.DefaultMinHours
+	}
+	if min < 0 {
+		min = 0
+	}
+
+	max = emp.MaxHours
+	if max <= 0 {
+		max = data.DefaultMaxHours
+	}
+	if constraints.MaxHoursPerEmployee > 0 && (max <= 0 || max > constraints.MaxHoursPerEmployee) {
+		max = constraints.MaxHoursPerEmployee
+	}
+	if max <= 0 {
+		max = Unlimited
+	}
+	return min, max
+}
+
+type bounds struct {
+	min, max int
+}
+
+func (b bounds) clamp(hours int) int {
+	if hours < b.min {
+		return b.min
+	}
+	if b.max != Unlimited && hours > b.max {
+		return b.max
+	}
+	return hours
+}
+
+// allocateWithinLimits ساعات را با روش توزیع واحد تقسیم می‌کند و سپس افرادی که از حداقل یا سقف
+// خود خارج شده‌اند را روی همان مرز ثابت کرده و مازاد یا کسری را دوباره بین بقیه پخش می‌کند.
+func allocateWithinLimits(result *Result, data core.DepartmentData, unlockedIndices []int, total int, limits []bounds) []int {
+	shares := make([]int, len(unlockedIndices))
+
+	sumMin, sumMax, maxUnlimited := 0, 0, false
+	for _, b := range limits {
+		sumMin += b.min
+		if b.max == Unlimited {
+			maxUnlimited = true
+		} else {
+			sumMax += b.max
+		}
+	}
+	if sumMin > total {
+		result.addDiagnostic(SeverityError, "", "سرانه باقیمانده (%d) برای پوشش حداقل ساعات تضمین شده (%d) کافی نیست.", total, sumMin)
+		for n, b := range limits {
+			shares[n] = b.min
+		}
+		return shares
+	}
+	if !maxUnlimited && sumMax < total {
+		result.addDiagnostic(SeverityError, "", "با سقف‌های تعیین شده فقط %d ساعت از سرانه باقیمانده (%d) قابل تخصیص است.", sumMax, total)
+		for n, b := range limits {
+			shares[n] = b.max
+		}
+		return shares
+	}
+
+	fixed := make([]bool, len(unlockedIndices))
+	diagnosticsSink := result
+	for {
+		var freePositions []int
+		var freeIndices []int
+		budget := total
+		for n, idx := range unlockedIndices {
+			if fixed[n] {
+				budget -= shares[n]
+				continue
+			}
+			freePositions = append(freePositions, n)
+			freeIndices = append(freeIndices, idx)
+		}
+		if len(freePositions) == 0 {
+			return shares
+		}
+
+		// پیام‌های روش توزیع فقط یک بار (در دور اول) ثبت می‌شوند.
+		freeShares := distribute(diagnosticsSink, data, freeIndices, budget)
+		diagnosticsSink = &Result{}
+
+		overflow, underflow := false, false
+		for k, n := range freePositions {
+			shares[n] = freeShares[k]
+			if limits[n].max != Unlimited && shares[n] > limits[n].max {
+				overflow = true
+			} else if shares[n] < limits[n].min {
+				underflow = true
+			}
+		}
+		if !overflow && !underflow {
+			return shares
+		}
+
+		// ابتدا سقف‌ها و سپس حداقل‌ها ثابت می‌شوند؛ در هر دور حداقل یک نفر ثابت می‌شود.
+		for _, n := range freePositions {
+			if overflow && limits[n].max != Unlimited && shares[n] > limits[n].max {
+				shares[n] = limits[n].max
+				fixed[n] = true
+			} else if !overflow && shares[n] < limits[n].min {
+				shares[n] = limits[n].min
+				fixed[n] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package allocation
+
+import (
+	"math"
+	"sort"
+
+	"overtime_go/core"
+)
+
+// distribute ساعات باقیمانده را بر اساس روش توزیع واحد بین پرسنل قفل نشده تقسیم می‌کند.
+// خروجی هم‌اندازه و هم‌ترتیب با unlockedIndices است.
+func distribute(result *Result, data core.DepartmentData, unlockedIndices []int, total int) []int {
+	employees := data.Employees
+	switch data.Strategy {
+	case "", core.StrategyEqual:
+		return equalSplit(total, len(unlockedIndices))
+
+	case core.StrategyWeighted:
+		weights := make([]float64, len(unlockedIndices))
+		for n, idx := range unlockedIndices {
+			weights[n] = employees[idx].Weight
+		}
+		if shares, ok := proportionalSplit(total, weights); ok {
+			return shares
+		}
+		result.addDiagnostic(SeverityWarning, "", "وزن هیچ‌یک از پرسنل قفل نشده مثبت نیست؛ تقسیم مساوی انجام شد.")
+		return equalSplit(total, len(unlockedIndices))
+
+	case core.StrategyProductionDays:
+		weights := make([]float64, len(unlockedIndices))
+		for n, idx := range unlockedIndices {
+			weights[n] = float64(employees[idx].ProductionDays)
+		}
+		if shares, ok := proportionalSplit(total, weights); ok {
+			return shares
+		}
+		result.addDiagnostic(SeverityWarning, "", "روزهای کارکرد هیچ‌یک از پرسنل قفل نشده ثبت نشده است؛ تقسیم مساوی انجام شد.")
+		return equalSplit(total, len(unlockedIndices))
+
+	case core.StrategySeniority:
+		seniority := make([]int, len(unlockedIndices))
+		for n, idx := range unlockedIndices {
+			seniority[n] = employees[idx].Seniority
+		}
+		return senioritySplit(total, seniority)
+
+	case core.StrategyFillToCap:
+		if data.FillCapHours <= 0 {
+			result.addDiagnostic(SeverityError, "", "سقف روش \"%s\" تعیین نشده است؛ تقسیم مساوی انجام شد.", core.StrategyFillToCap.DisplayName())
+			return equalSplit(total, len(unlockedIndices))
+		}
+		shares, leftover := fillToCap(total, len(unlockedIndices), data.FillCapHours)
+		if leftover > 0 {
+			result.addDiagnostic(SeverityWarning, "", "با سقف %d ساعت برای هر نفر، %d ساعت از سرانه توزیع نشد.", data.FillCapHours, leftover)
+		}
+		return shares
+	}
+
+	result.addDiagnostic(SeverityWarning, "", "روش توزیع '%s' شناخته شده نیست؛ تقسیم مساوی انجام شد.", data.Strategy)
+	return equalSplit(total, len(unlockedIndices))
+}
+
+// equalSplit تقسیم صحیح مساوی؛ باقیمانده به ترتیب به اولین نفرات می‌رسد.
+func equalSplit(total, count int) []int {
+	shares := make([]int, count)
+	if count == 0 {
+		return shares
+	}
+	base := total / count
+	extra := total % count
+	for n := range shares {
+		shares[n] = base
+		if n < extra {
+			shares[n]++
+		}
+	}
+	return shares
+}
+
+// proportionalSplit تقسیم متناسب با وزن به روش بزرگ‌ترین باقیمانده.
+// در تساوی کسرها، نفر جلوتر در لیست اولویت دارد. اگر مجموع وزن‌ها مثبت نباشد ok برابر false است.
+func proportionalSplit(total int, weights []float64) (shares []int, ok bool) {
+	weightSum := 0.0
+	for _, w := range weights {
+		if w > 0 {
+			weightSum += w
+		}
+	}
+	if weightSum <= 0 {
+		return nil, false
+	}
+
+	shares = make([]int, len(weights))
+	fractions := make([]float64, len(weights))
+	assigned := 0
+	for n, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		exact := float64(total) * w / weightSum
+		shares[n] = int(math.Floor(exact))
+		fractions[n] = exact - float64(shares[n])
+		assigned += shares[n]
+	}
+
+	order := make([]int, len(weights))
+	for n := range order {
+		order[n] = n
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return fractions[order[a]] > fractions[order[b]]
+	})
+	for _, n := range order {
+		if assigned >= total {
+			break
+		}
+		if weights[n] <= 0 {
+			continue
+		}
+		shares[n]++
+		assigned++
+	}
+	return shares, true
+}
+
+// senioritySplit تقسیم مساوی که باقیمانده آن به صورت نوبتی به باسابقه‌ترین افراد می‌رسد.
+func senioritySplit(total int, seniority []int) []int {
+	count := len(seniority)
+	shares := make([]int, count)
+	if count == 0 {
+		return shares
+	}
+	base := total / count
+	extra := total % count
+	for n := range shares {
+		shares[n] = base
+	}
+
+	order := make([]int, count)
+	for n := range order {
+		order[n] = n
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return seniority[order[a]] > seniority[order[b]]
+	})
+	for _, n := range order[:extra] {
+		shares[n]++
+	}
+	return shares
+}
+
+// fillToCap به ترتیب لیست، هر نفر را تا سقف پر می‌کند تا سرانه تمام شود.
+func fillToCap(total, count, capHours int) (shares []int, leftover int) {
+	shares = make([]int, count)
+	remaining := total
+	for n := range shares {
+		if remaining <= 0 {
+			break
+		}
+		give := capHours
+		if remaining < give {
+			give = remaining
+		}
+		shares[n] = give
+		remaining -= give
+	}
+	return shares, remaining
+}
@@ -0,0 +1,283 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"overtime_go/auth"
+	"overtime_go/storage"
+)
+
+const auditLogFilename = "audit_log.jsonl"
+
+// chainKeyPurpose نام کاربرد کلید مشتق شده از کلید نصب برای زنجیره گزارش حسابرسی است.
+const chainKeyPurpose = "overtime-audit-chain-v1"
+
+// Source منبع (نوع عملیات) یک تغییر ثبت شده در گزارش حسابرسی است.
+type Source string
+
+const (
+	SourceTableEdit        Source = "table_edit"
+	SourceBudgetEdit       Source = "budget_edit"
+	SourceSettingsEdit     Source = "settings_edit"
+	SourceCloudUpdate      Source = "cloud_update"
+	SourceReset            Source = "reset"
+	SourceAdminImport      Source = "admin_import"
+	SourceAdminCreateTable Source = "admin_create_table"
+	SourceUndo             Source = "undo"
+	SourceRedo             Source = "redo"
+)
+
+// Sources همه منابع تغییر به ترتیب نمایش است.
+var Sources = []Source{SourceTableEdit, SourceBudgetEdit, SourceSettingsEdit, SourceCloudUpdate, SourceReset, SourceAdminImport, SourceAdminCreateTable, SourceUndo, SourceRedo}
+
+var sourceDisplayNames = map[Source]string{
+	SourceTableEdit:        "ویرایش جدول",
+	SourceBudgetEdit:       "ویرایش سرانه",
+	SourceSettingsEdit:     "تنظیمات توزیع",
+	SourceCloudUpdate:      "به‌روزرسانی از سرور",
+	SourceReset:            "پاک کردن جدول",
+	SourceAdminImport:      "ورود اکسل (ادمین)",
+	SourceAdminCreateTable: "ایجاد جدول دستی (ادمین)",
+	SourceUndo:             "بازگشت",
+	SourceRedo:             "انجام مجدد",
+}
+
+// DisplayName نام فارسی منبع تغییر را برمی‌گرداند.
+func (s Source) DisplayName() string {
+	if name, ok := sourceDisplayNames[s]; ok {
+		return name
+	}
+	return string(s)
+}
+
+// Entry یک ردیف گزارش حسابرسی است. Hash برابر HMAC-SHA256 ردیف (بدون Hash) به همراه PrevHash با کلید مشتق شده
+// از کلید نصب است، پس بدون آن کلید نمی‌توان ردیفی را تغییر داد و زنجیره را دوباره ساخت.
+// حذف ردیف‌های انتهایی با مقایسه با سر زنجیره ذخیره شده جداگانه (HeadStore) شناسایی می‌شود.
+type Entry struct {
+	Seq             int       `json:"seq"`
+	Timestamp       time.Time `json:"timestamp"`
+	Username        string    `json:"username"`
+	Source          Source    `json:"source"`
+	DepartmentShift string    `json:"department_shift"`
+	EmployeeID      string    `json:"employee_id,omitempty"`
+	EmployeeName    string    `json:"employee_name,omitempty"`
+	Field           string    `json:"field"`
+	OldValue        string    `json:"old_value"`
+	NewValue        string    `json:"new_value"`
+	PrevHash        string    `json:"prev_hash"`
+	Hash            string    `json:"hash"`
+}
+
+// computeHash هش کلیددار ردیف را بر اساس همه فیلدها به جز خود Hash محاسبه می‌کند.
+func (e Entry) computeHash(key []byte) string {
+	e.Hash = ""
+	payload, _ := json.Marshal(e)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HeadStore محل نگهداری شماره و هش آخرین ردیف ثبت شده، جدا از فایل گزارش است.
+type HeadStore struct {
+	Load func() (seq int, hash string)
+	Save func(seq int, hash string)
+}
+
+var (
+	logMu     sync.Mutex
+	logLoaded bool
+	lastSeq   int
+	lastHash  string
+	// headTrusted یعنی سر زنجیره فایل با سر ذخیره شده یکسان بود؛ در غیر این صورت سر ذخیره شده به‌روز نمی‌شود
+	// تا حذف ردیف‌ها با ثبت ردیف‌های جدید پوشانده نشود.
+	headTrusted bool
+	headStore   HeadStore
+)
+
+// InitializeHeadStore محل ذخیره سر زنجیره را تنظیم می‌کند. باید پیش از اولین Record فراخوانی شود.
+func InitializeHeadStore(store HeadStore) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	headStore = store
+	logLoaded = false
+}
+
+func storedHead() (int, string) {
+	if headStore.Load == nil {
+		return 0, ""
+	}
+	return headStore.Load()
+}
+
+func logFilePath() (string, error) {
+	dataDir, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, auditLogFilename), nil
+}
+
+// loadChainHead شماره و هش آخرین ردیف را یک بار از فایل می‌خواند تا ردیف‌های جدید به زنجیره متصل شوند.
+func loadChainHead() error {
+	if logLoaded {
+		return nil
+	}
+	entries, err := ReadAll()
+	if err != nil {
+		return err
+	}
+	lastSeq, lastHash = 0, ""
+	if n := len(entries); n > 0 {
+		lastSeq = entries[n-1].Seq
+		lastHash = entries[n-1].Hash
+	}
+	storedSeq, storedHash := storedHead()
+	headTrusted = storedSeq == 0 || (storedSeq == lastSeq && storedHash == lastHash)
+	if !headTrusted {
+		fmt.Printf("هشدار: سر زنجیره گزارش حسابرسی (ردیف %d) با ردیف ذخیره شده (%d) یکسان نیست؛ سر ذخیره شده به‌روز نمی‌شود.\n", lastSeq, storedSeq)
+	}
+	logLoaded = true
+	return nil
+}
+
+// Record تغییرات یک واحد را با کاربر و منبع داده شده به انتهای گزارش حسابرسی اضافه می‌کند.
+func Record(username string, source Source, deptShift string, changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	logMu.Lock()
+	defer logMu.Unlock()
+
+	if err := loadChainHead(); err != nil {
+		return err
+	}
+	key, err := auth.DerivedKey(chainKeyPurpose)
+	if err != nil {
+		return fmt.Errorf("خطا در گرفتن کلید گزارش حسابرسی: %w", err)
+	}
+	filePath, err := logFilePath()
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("خطا در باز کردن گزارش حسابرسی '%s': %w", filePath, err)
+	}
+	defer file.Close()
+
+	now := time.Now()
+	writer := bufio.NewWriter(file)
+	seq, prevHash := lastSeq, lastHash
+	for _, c := range changes {
+		seq++
+		entry := Entry{
+			Seq:             seq,
+			Timestamp:       now,
+			Username:        username,
+			Source:          source,
+			DepartmentShift: deptShift,
+			EmployeeID:      c.EmployeeID,
+			EmployeeName:    c.EmployeeName,
+			Field:           c.Field,
+			OldValue:        c.OldValue,
+			NewValue:        c.NewValue,
+			PrevHash:        prevHash,
+		}
+		entry.Hash = entry.computeHash(key)
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("خطا در تبدیل ردیف گزارش حسابرسی به JSON: %w", err)
+		}
+		writer.Write(line)
+		writer.WriteByte('\n')
+		prevHash = entry.Hash
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("خطا در نوشتن گزارش حسابرسی '%s': %w", filePath, err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("خطا در نوشتن گزارش حسابرسی '%s': %w", filePath, err)
+	}
+	lastSeq, lastHash = seq, prevHash
+	if headTrusted && headStore.Save != nil {
+		headStore.Save(lastSeq, lastHash)
+	}
+	return nil
+}
+
+// ReadAll همه ردیف‌های گزارش حسابرسی را به ترتیب ثبت می‌خواند.
+func ReadAll() ([]Entry, error) {
+	filePath, err := logFilePath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("خطا در خواندن گزارش حسابرسی '%s': %w", filePath, err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return entries, fmt.Errorf("ردیف %d گزارش حسابرسی قابل خواندن نیست: %w", lineNumber, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("خطا در خواندن گزارش حسابرسی '%s': %w", filePath, err)
+	}
+	return entries, nil
+}
+
+// Verify زنجیره هش ردیف‌ها و سر زنجیره ذخیره شده را بررسی می‌کند و در صورت دستکاری،
+// خطایی با شماره اولین ردیف نامعتبر برمی‌گرداند.
+func Verify(entries []Entry) error {
+	key, err := auth.DerivedKey(chainKeyPurpose)
+	if err != nil {
+		return fmt.Errorf("خطا در گرفتن کلید گزارش حسابرسی: %w", err)
+	}
+	prevHash := ""
+	for i, e := range entries {
+		if e.Seq != i+1 {
+			return fmt.Errorf("ردیف %d: شماره ردیف (%d) پیوسته نیست؛ ردیفی حذف یا اضافه شده است", i+1, e.Seq)
+		}
+		if e.PrevHash != prevHash {
+			return fmt.Errorf("ردیف %d: اتصال به ردیف قبلی نامعتبر است", e.Seq)
+		}
+		if e.computeHash(key) != e.Hash {
+			return fmt.Errorf("ردیف %d: محتوای ردیف پس از ثبت تغییر کرده است", e.Seq)
+		}
+		prevHash = e.Hash
+	}
+	storedSeq, storedHash := storedHead()
+	if storedSeq > 0 {
+		if len(entries) < storedSeq {
+			return fmt.Errorf("گزارش %d ردیف دارد ولی تا ردیف %d ثبت شده بود؛ ردیف‌های انتهایی حذف شده‌اند", len(entries), storedSeq)
+		}
+		if entries[storedSeq-1].Hash != storedHash {
+			return fmt.Errorf("ردیف %d: با سر زنجیره ذخیره شده یکسان نیست", storedSeq)
+		}
+	}
+	return nil
+}
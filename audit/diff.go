@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"strconv"
+
+	"overtime_go/core"
+)
+
+// Change یک تغییر در داده‌های یک واحد است. EmployeeID برای تغییرات سطح واحد (مانند سرانه) خالی است.
+type Change struct {
+	EmployeeID   string
+	EmployeeName string
+	Field        string
+	OldValue     string
+	NewValue     string
+}
+
+// نام فیلدهای ثبت شده در گزارش حسابرسی
+const (
+	FieldTotalHours      = "سرانه"
+	FieldProductionDays  = "روزهای تولید"
+	FieldPeriod          = "دوره"
+	FieldStrategy        = "روش توزیع"
+	FieldFillCapHours    = "سقف پر کردن"
+	FieldDefaultMinHours = "حداقل پیش‌فرض"
+	FieldDefaultMaxHours = "سقف پیش‌فرض"
+	FieldEmployee        = "پرسنل"
+	FieldHours           = "ساعت اضافه کاری"
+	FieldLocked          = "قفل"
+	FieldMinHours        = "حداقل ساعت"
+	FieldMaxHours        = "سقف ساعت"
+)
+
+func employeeKey(emp core.Employee) string {
+	if emp.ID != "" {
+		return emp.ID
+	}
+	return "name:" + emp.Name
+}
+
+// DiffDepartment تغییرات بین دو نسخه از داده‌های یک واحد را برمی‌گرداند. پرسنل با کد پرسنلی
+// (یا در نبود آن، با نام) تطبیق داده می‌شوند.
+func DiffDepartment(before, after core.DepartmentData) []Change {
+	var changes []Change
+	addInt := func(field string, oldValue, newValue int) {
+		if oldValue != newValue {
+			changes = append(changes, Change{Field: field, OldValue: strconv.Itoa(oldValue), NewValue: strconv.Itoa(newValue)})
+		}
+	}
+	addInt(FieldTotalHours, before.TotalHours, after.TotalHours)
+	addInt(FieldProductionDays, before.ProductionDays, after.ProductionDays)
+	if before.Period != after.Period {
+		changes = append(changes, Change{Field: FieldPeriod, OldValue: before.Period.String(), NewValue: after.Period.String()})
+	}
+	if before.Strategy != after.Strategy {
+		changes = append(changes, Change{Field: FieldStrategy, OldValue: before.Strategy.DisplayName(), NewValue: after.Strategy.DisplayName()})
+	}
+	addInt(FieldFillCapHours, before.FillCapHours, after.FillCapHours)
+	addInt(FieldDefaultMinHours, before.DefaultMinHours, after.DefaultMinHours)
+	addInt(FieldDefaultMaxHours, before.DefaultMaxHours, after.DefaultMaxHours)
+
+	oldEmployees := make(map[string]core.Employee, len(before.Employees))
+	for _, emp := range before.Employees {
+		oldEmployees[employeeKey(emp)] = emp
+	}
+	seen := make(map[string]bool, len(after.Employees))
+	for _, emp := range after.Employees {
+		key := employeeKey(emp)
+		seen[key] = true
+		old, existed := oldEmployees[key]
+		if !existed {
+			changes = append(changes, Change{EmployeeID: emp.ID, EmployeeName: emp.Name, Field: FieldEmployee, NewValue: "افزوده شد"})
+			if emp.Hours != 0 {
+				changes = append(changes, Change{EmployeeID: emp.ID, EmployeeName: emp.Name, Field: FieldHours, OldValue: "0", NewValue: strconv.Itoa(emp.Hours)})
+			}
+			continue
+		}
+		addEmp := func(field, oldValue, newValue string) {
+			if oldValue != newValue {
+				changes = append(changes, Change{EmployeeID: emp.ID, EmployeeName: emp.Name, Field: field, OldValue: oldValue, NewValue: newValue})
+			}
+		}
+		addEmp(FieldHours, strconv.Itoa(old.Hours), strconv.Itoa(emp.Hours))
+		addEmp(FieldLocked, strconv.FormatBool(old.Locked), strconv.FormatBool(emp.Locked))
+		addEmp(FieldMinHours, strconv.Itoa(old.MinHours), strconv.Itoa(emp.MinHours))
+		addEmp(FieldMaxHours, strconv.Itoa(old.MaxHours), strconv.Itoa(emp.MaxHours))
+	}
+	for _, emp := range before.Employees {
+		if !seen[employeeKey(emp)] {
+			changes = append(changes, Change{EmployeeID: emp.ID, EmployeeName: emp.Name, Field: FieldEmployee, OldValue: strconv.Itoa(emp.Hours) + " ساعت", NewValue: "حذف شد"})
+		}
+	}
+	return changes
+}
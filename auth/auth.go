@@ -2,75 +2,140 @@ package auth
 
 import (
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
 	"overtime_go/core" // برای دسترسی به core.User
+
+	"golang.org/x/crypto/bcrypt"
 )
 
-// اطلاعات کاربران پیش‌فرض (رمزها باید در زمان اجرا هش شوند)
+// کاربران اولیه فقط در اولین اجرا (وقتی فایل کاربران وجود ندارد) در فایل ذخیره می‌شوند.
+// رمزها به صورت هش bcrypt نگهداری می‌شوند تا رمز متنی در فایل اجرایی وجود نداشته باشد.
+// افزودن، غیرفعال کردن یا تغییر واحد کاربران از طریق فایل users_import.json انجام می‌شود.
 var (
 	// نقش‌ها: "admin", "department_head"
 	// دپارتمان: "all" برای ادمین، نام فارسی واحد برای رؤسا
-	defaultRawUsers = map[string]struct {
-		Password   string
-		Role       string
-		Department string
+	defaultSeedUsers = map[string]struct {
+		PasswordHash string
+		Role         string
+		Department   string
 	}{
-		"admin":           {"Admin@1371", "admin", "all"},
-		"technicaloffice": {"Technicaloffice@3668", "department_head", "دفتر فنی"},
-		"hr":              {"Hr@9610", "department_head", "سرمایه های انسانی"},
-		"production":      {"Production@1427", "department_head", "تولید"},
-		// سایر کاربران پیش‌فرض ...
-		// "warehouse": {"Warehouse@8902", "department_head", "انبار"},
-		// "security": {"Security@6285", "department_head", "حراست"},
-		// "electrical": {"Electrical@3937", "department_head", "برق"},
-		// "facilities": {"Facilities@1121", "department_head", "تأسیسات"},
-		// "mechanical": {"Mechanical@4451", "department_head", "مکانیک"},
-		// "qc": {"Qc@4673", "department_head", "کنترل کیفیت"},
-		// "machining": {"Machining@5110", "department_head", "تراشکاری"},
-		// "maintenance": {"Maintenance@1064", "department_head", "نت"},
-		// "se": {"Se@9743", "department_head", "مهندسی سیستم"},
-		// "it": {"It@1277", "department_head", "فناوری اطلاعات"},
-		// "planning": {"Planning@4595", "department_head", "برنامه ریزی"},
-		// "management": {"Management@6643", "department_head", "مدیریت"},
-		// "sales": {"Sales@2817", "department_head", "فروش"},
-		// "logistics": {"Logistics@7677", "department_head", "تدارکات"},
-		// "finance": {"Finance@3115", "department_head", "مالی"},
-		// "hse": {"Hse@4011", "department_head", "HSE"},
-		// "technicalengineering": {"Technicalengineering@5432", "department_head", "فنی مهندسی"}, // این نقش خاص است
-
+		"admin":           {"$2a$10$af2PawHAu50EBJTUs2HCzOFX/Qla23AwrbZHE.TX9w8DE6lEwIZlK", "admin", "all"},
+		"technicaloffice": {"$2a$10$JgKYHFUSeP2UwK8zTPZ9U.zT7YAEXMwRHXrVOKMQ3XfiHWgxgR81S", "department_head", "دفتر فنی"},
+		"hr":              {"$2a$10$kPKza8ub1zgOoueeGmFb6u0/a8UlY8tdkwaQIas9cimEHjkPlQ2ye", "department_head", "سرمایه های انسانی"},
+		"production":      {"$2a$10$BMssXcIAEJQt5IFRfL1BrOI6rFskus2k1A4x8hf8c9Zy0kBFkTrW6", "department_head", "تولید"},
 	}
 	// کاربران با رمزهای هش شده در حافظه
 	ProcessedUsers = make(map[string]core.User)
 )
 
-// HashPassword رمز عبور را با SHA256 هش می‌کند.
-func HashPassword(password string) string {
+// dummyPasswordHash برای کاربران ناموجود مقایسه می‌شود تا زمان پاسخ، وجود نام کاربری را لو ندهد.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("overtime-dummy-password"), bcrypt.DefaultCost)
+
+// HashPassword رمز عبور را با bcrypt (نمک تصادفی و هزینه محاسباتی بالا) هش می‌کند.
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("خطا در هش کردن رمز عبور: %w", err)
+	}
+	return string(hashed), nil
+}
+
+// legacyHashPassword هش قدیمی SHA256 بدون نمک است و فقط برای بررسی و ارتقای هش‌های قدیمی استفاده می‌شود.
+func legacyHashPassword(password string) string {
 	hasher := sha256.New()
 	hasher.Write([]byte(password))
 	return hex.EncodeToString(hasher.Sum(nil))
 }
 
-// InitializeDefaultUsers رمزهای پیش‌فرض را هش و در ProcessedUsers ذخیره می‌کند.
-func InitializeDefaultUsers() {
-	for username, u := range defaultRawUsers {
-		ProcessedUsers[username] = core.User{
-			Username:   username,
-			Password:   HashPassword(u.Password),
-			Role:       u.Role,
-			Department: u.Department,
+// isLegacyHash مشخص می‌کند که هش ذخیره شده از نوع SHA256 قدیمی است.
+func isLegacyHash(storedHash string) bool {
+	if len(storedHash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(storedHash)
+	return err == nil
+}
+
+// verifyPassword رمز عبور را با هش ذخیره شده مقایسه می‌کند. needsUpgrade برای هش‌های قدیمی true است.
+func verifyPassword(storedHash, password string) (ok, needsUpgrade bool) {
+	if isLegacyHash(storedHash) {
+		candidate := legacyHashPassword(password)
+		return subtle.ConstantTimeCompare([]byte(storedHash), []byte(candidate)) == 1, true
+	}
+	return bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(password)) == nil, false
+}
+
+// InitializeDefaultUsers کاربران را از فایل کاربران بارگذاری می‌کند. اگر فایل وجود نداشته باشد
+// (اولین اجرا)، کاربران اولیه در آن ذخیره می‌شوند. سپس فایل users_import.json (در صورت وجود) اعمال می‌شود.
+func InitializeDefaultUsers() error {
+	users, err := loadUserStore()
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		fmt.Println("فایل کاربران یافت نشد؛ کاربران اولیه ایجاد می‌شوند.")
+		users = make(map[string]core.User)
+		for username, u := range defaultSeedUsers {
+			users[username] = core.User{
+				Username:   username,
+				Password:   u.PasswordHash,
+				Role:       u.Role,
+				Department: u.Department,
+			}
+		}
+		ProcessedUsers = users
+		if err := SaveUsers(); err != nil {
+			return err
 		}
+	} else {
+		ProcessedUsers = users
+	}
+
+	if err := applyUserImportFile(); err != nil {
+		fmt.Printf("هشدار: خطا در اعمال فایل %s: %v\n", userImportFilename, err)
 	}
+	return nil
 }
 
+// ErrInvalidCredentials برای نام کاربری یا رمز عبور اشتباه (و حساب غیرفعال) برگردانده می‌شود.
+var ErrInvalidCredentials = errors.New("نام کاربری یا رمز عبور اشتباه است.")
+
 // AuthenticateUser بررسی می‌کند که آیا نام کاربری و رمز عبور معتبر هستند.
-func AuthenticateUser(username, password string) (*core.User, bool) {
+// تلاش‌های ناموفق برای هر نام کاربری شمرده می‌شوند و در صورت محدود بودن ورود، *LoginThrottledError برمی‌گردد.
+// هش‌های قدیمی SHA256 پس از ورود موفق به صورت خودکار به bcrypt ارتقا می‌یابند.
+func AuthenticateUser(username, password string) (*core.User, error) {
+	if err := checkLoginAllowed(username); err != nil {
+		return nil, err
+	}
+
 	user, exists := ProcessedUsers[username]
 	if !exists {
-		return nil, false
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		recordLoginFailure(username)
+		return nil, ErrInvalidCredentials
 	}
 
-	if user.Password == HashPassword(password) {
-		return &user, true
+	ok, needsUpgrade := verifyPassword(user.Password, password)
+	if !ok || user.Disabled {
+		recordLoginFailure(username)
+		return nil, ErrInvalidCredentials
+	}
+	clearLoginFailures(username)
+	if needsUpgrade {
+		if upgraded, err := HashPassword(password); err == nil {
+			user.Password = upgraded
+			ProcessedUsers[username] = user
+			if err := SaveUsers(); err != nil {
+				fmt.Printf("هشدار: ذخیره هش ارتقا یافته کاربر '%s' انجام نشد: %v\n", username, err)
+			}
+			fmt.Printf("هش رمز کاربر '%s' به bcrypt ارتقا یافت.\n", username)
+		} else {
+			fmt.Printf("هشدار: ارتقای هش رمز کاربر '%s' انجام نشد: %v\n", username, err)
+		}
 	}
-	return nil, false
+	return &user, nil
 }
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"overtime_go/utils"
+)
+
+const installKeyFilename = "install.key"
+
+var (
+	installKeyOnce  sync.Once
+	installKeyValue []byte
+	installKeyErr   error
+)
+
+// appFilePath مسیر یک فایل کنار فایل اجرایی برنامه را برمی‌گرداند (مانند cloud_links.json).
+func appFilePath(filename string) string {
+	appDir, err := utils.GetExecutableDir()
+	if err != nil {
+		fmt.Printf("هشدار: خطا در گرفتن مسیر فایل اجرایی برای %s: %v. تلاش برای مسیر فعلی.\n", filename, err)
+		appDir, _ = os.Getwd()
+	}
+	return filepath.Join(appDir, filename)
+}
+
+// installKey کلید تصادفی 32 بایتی مختص این نصب را برمی‌گرداند و در اولین استفاده آن را می‌سازد.
+// کلید کنار فایل اجرایی و در کنار users.dat نگهداری می‌شود تا همه حساب‌های ویندوز روی یک نصب مشترک
+// به همان کلید و فایل کاربران دسترسی داشته باشند. پس رمزنگاری فایل کاربران فقط یک پوشاندن در برابر
+// خواندن یا ویرایش اتفاقی است و جایگزین محدود کردن دسترسی به پوشه برنامه نیست.
+func installKey() ([]byte, error) {
+	installKeyOnce.Do(func() {
+		keyPath := appFilePath(installKeyFilename)
+		key, err := os.ReadFile(keyPath)
+		if err == nil && len(key) == 32 {
+			installKeyValue = key
+			return
+		}
+		if err == nil {
+			installKeyErr = fmt.Errorf("فایل کلید '%s' نامعتبر است", keyPath)
+			return
+		}
+		if !os.IsNotExist(err) {
+			installKeyErr = fmt.Errorf("خطا در خواندن فایل کلید '%s': %w", keyPath, err)
+			return
+		}
+
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			installKeyErr = fmt.Errorf("خطا در تولید کلید نصب: %w", err)
+			return
+		}
+		if err := os.WriteFile(keyPath, key, 0600); err != nil {
+			installKeyErr = fmt.Errorf("خطا در ذخیره فایل کلید '%s': %w", keyPath, err)
+			return
+		}
+		fmt.Printf("کلید جدید نصب در '%s' ایجاد شد.\n", keyPath)
+		installKeyValue = key
+	})
+	return installKeyValue, installKeyErr
+}
+
+// DerivedKey یک کلید 32 بایتی برای کاربرد purpose از کلید نصب مشتق می‌کند تا هر کاربرد کلید جداگانه‌ای داشته باشد.
+func DerivedKey(purpose string) ([]byte, error) {
+	key, err := installKey()
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil), nil
+}
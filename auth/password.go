@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MinPasswordLength حداقل طول رمز عبور است.
+const MinPasswordLength = 8
+
+// PasswordPolicyDescription شرح قوانین رمز عبور برای نمایش به کاربر است.
+var PasswordPolicyDescription = fmt.Sprintf("رمز عبور باید حداقل %d نویسه و شامل حرف بزرگ و کوچک لاتین، رقم و نماد (مانند @) باشد.", MinPasswordLength)
+
+// ValidatePasswordPolicy بررسی می‌کند که رمز عبور با قالب رمزهای پیش‌فرض (حرف بزرگ، حرف کوچک، رقم و نماد) سازگار باشد.
+func ValidatePasswordPolicy(password string) error {
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		return fmt.Errorf("رمز عبور باید حداقل %d نویسه باشد", MinPasswordLength)
+	}
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case r > unicode.MaxASCII:
+			return fmt.Errorf("رمز عبور فقط می‌تواند شامل حروف لاتین، ارقام و نمادها باشد")
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		case unicode.IsSpace(r):
+			return fmt.Errorf("رمز عبور نمی‌تواند شامل فاصله باشد")
+		}
+	}
+	switch {
+	case !hasUpper:
+		return fmt.Errorf("رمز عبور باید حداقل یک حرف بزرگ لاتین داشته باشد")
+	case !hasLower:
+		return fmt.Errorf("رمز عبور باید حداقل یک حرف کوچک لاتین داشته باشد")
+	case !hasDigit:
+		return fmt.Errorf("رمز عبور باید حداقل یک رقم داشته باشد")
+	case !hasSymbol:
+		return fmt.Errorf("رمز عبور باید حداقل یک نماد (مانند @ یا #) داشته باشد")
+	}
+	return nil
+}
+
+// ChangePassword رمز عبور کاربر را پس از بررسی رمز فعلی تغییر می‌دهد و الزام تغییر رمز را برمی‌دارد.
+func ChangePassword(username, currentPassword, newPassword string) error {
+	user, err := lookupUser(username)
+	if err != nil {
+		return err
+	}
+	if ok, _ := verifyPassword(user.Password, currentPassword); !ok {
+		return fmt.Errorf("رمز عبور فعلی اشتباه است")
+	}
+	if newPassword == currentPassword {
+		return fmt.Errorf("رمز عبور جدید باید با رمز فعلی متفاوت باشد")
+	}
+	if err := ValidatePasswordPolicy(newPassword); err != nil {
+		return err
+	}
+	hashed, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	user.Password = hashed
+	user.MustChangePassword = false
+	user.SessionGeneration++
+	return saveUserChange(username, &user)
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"overtime_go/core"
+)
+
+// Capability یک توانمندی قابل اعطا به نقش‌ها است. همه بررسی‌های دسترسی (در رابط کاربری و هر ورودی دیگر)
+// باید از طریق Can، CanAccessDepartmentShift یا Authorize انجام شوند، نه مقایسه مستقیم نام نقش.
+type Capability string
+
+const (
+	CapView           Capability = "view"            // مشاهده جدول تخصیص واحدهای قابل دسترسی
+	CapAllDepartments Capability = "all-departments" // دسترسی به همه واحدها بدون نیاز به فهرست دسترسی
+	CapViewSummary    Capability = "view-summary"    // مشاهده خلاصه تجمیعی تخصیص همه واحدهای قابل دسترسی
+	CapEditAllocation Capability = "edit-allocation" // ویرایش ساعات، قفل، روش توزیع و پاک کردن جدول
+	CapEditBudget     Capability = "edit-budget"     // ویرایش سرانه و حداقل/سقف پیش‌فرض واحد
+	CapImport         Capability = "import"          // ایجاد جدول دستی و ورود اطلاعات از اکسل
+	CapCloudUpdate    Capability = "cloud-update"    // به‌روزرسانی اطلاعات واحد از سرور
+	CapManageLinks    Capability = "manage-links"    // مدیریت لینک‌های دانلود سرور
+	CapManageUsers    Capability = "manage-users"    // مدیریت کاربران
+	CapManageOrg      Capability = "manage-org"      // ویرایش ساختار سازمانی (واحدها، شیفت‌ها و گروه‌ها)
+	CapExport         Capability = "export"          // خروجی اکسل و بایگانی تخصیص نهایی
+	CapViewAudit      Capability = "view-audit"      // مشاهده و خروجی گرفتن از گزارش حسابرسی تغییرات
+)
+
+const rolesOverrideFilename = "roles.json"
+
+// RoleDefinition تعریف یک نقش در فایل نقش‌ها است.
+type RoleDefinition struct {
+	DisplayName  string       `json:"display_name"`
+	Capabilities []Capability `json:"capabilities"`
+}
+
+type rolesFile struct {
+	Roles map[string]RoleDefinition `json:"roles"`
+}
+
+var configuredRoles = make(map[string]RoleDefinition)
+
+// InitializeRoles نقش‌ها را از فایل پیش‌فرض جاسازی شده بارگذاری می‌کند. اگر فایل roles.json کنار برنامه
+// وجود داشته باشد، نقش‌های آن جایگزین یا اضافه می‌شوند.
+func InitializeRoles(defaultRolesJSON []byte) error {
+	var defaults rolesFile
+	if err := json.Unmarshal(defaultRolesJSON, &defaults); err != nil {
+		return fmt.Errorf("خطا در پارس کردن نقش‌های پیش‌فرض: %w", err)
+	}
+	configuredRoles = defaults.Roles
+	if configuredRoles == nil {
+		configuredRoles = make(map[string]RoleDefinition)
+	}
+
+	overridePath := appFilePath(rolesOverrideFilename)
+	fileData, err := os.ReadFile(overridePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("هشدار: خطا در خواندن '%s': %v. استفاده از نقش‌های پیش‌فرض.\n", overridePath, err)
+		}
+		return nil
+	}
+	var overrides rolesFile
+	if err := json.Unmarshal(fileData, &overrides); err != nil {
+		fmt.Printf("هشدار: خطا در پارس کردن '%s': %v. استفاده از نقش‌های پیش‌فرض.\n", overridePath, err)
+		return nil
+	}
+	for name, role := range overrides.Roles {
+		configuredRoles[name] = role
+	}
+	fmt.Printf("%d نقش از فایل '%s' اعمال شد.\n", len(overrides.Roles), overridePath)
+	return nil
+}
+
+// RoleNames نام نقش‌های تعریف شده را به ترتیب الفبا برمی‌گرداند.
+func RoleNames() []string {
+	names := make([]string, 0, len(configuredRoles))
+	for name := range configuredRoles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RoleDisplayName نام نمایشی نقش را برمی‌گرداند؛ برای نقش تعریف نشده، خود نام نقش.
+func RoleDisplayName(role string) string {
+	if def, ok := configuredRoles[role]; ok && def.DisplayName != "" {
+		return def.DisplayName
+	}
+	return role
+}
+
+// IsValidRole مشخص می‌کند که نقش در فایل نقش‌ها تعریف شده است.
+func IsValidRole(role string) bool {
+	_, ok := configuredRoles[role]
+	return ok
+}
+
+// RoleHasCapability مشخص می‌کند که نقش توانمندی داده شده را دارد.
+func RoleHasCapability(role string, capability Capability) bool {
+	for _, c := range configuredRoles[role].Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Can مشخص می‌کند که کاربر توانمندی داده شده را دارد. کاربر غیرفعال هیچ توانمندی ندارد.
+func Can(user *core.User, capability Capability) bool {
+	if user == nil || user.Disabled {
+		return false
+	}
+	return RoleHasCapability(user.Role, capability)
+}
+
+// AccessibleDepartmentShifts واحد-شیفت‌هایی که کاربر به آن‌ها دسترسی دارد را به ترتیب الفبا برمی‌گرداند.
+// ترتیب تعیین دسترسی: توانمندی all-departments، سپس فهرست دسترسی صریح کاربر (DepartmentShiftAccess)،
+// و در غیر این صورت واحد یا گروه ویژه (core.DepartmentGroups) تعیین شده در Department.
+func AccessibleDepartmentShifts(user *core.User) []string {
+	if !Can(user, CapView) {
+		return nil
+	}
+	if Can(user, CapAllDepartments) {
+		// کپی برگردانده می‌شود تا مرتب‌سازی یا افزودن توسط فراخواننده، فهرست سراسری واحدها را تغییر ندهد.
+		return append([]string(nil), core.ManageableDepartments...)
+	}
+
+	var accessible []string
+	if len(user.DepartmentShiftAccess) > 0 {
+		for _, deptShift := range user.DepartmentShiftAccess {
+			if isManageableDepartmentShift(deptShift) {
+				accessible = append(accessible, deptShift)
+			} else {
+				fmt.Printf("هشدار: واحد-شیفت '%s' در فهرست دسترسی کاربر '%s' تعریف نشده است.\n", deptShift, user.Username)
+			}
+		}
+	} else if groupShifts, isGroup := core.DepartmentGroups[user.Department]; isGroup {
+		accessible = append(accessible, groupShifts...)
+	} else if shifts, ok := core.DepartmentShifts[user.Department]; ok {
+		for _, shift := range shifts {
+			accessible = append(accessible, user.Department+" - "+shift)
+		}
+	} else {
+		fmt.Printf("خطا: واحد سازمانی '%s' برای کاربر تعریف نشده است.\n", user.Department)
+	}
+	sort.Strings(accessible)
+	return accessible
+}
+
+func isManageableDepartmentShift(deptShift string) bool {
+	for _, d := range core.ManageableDepartments {
+		if d == deptShift {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessDepartmentShift مشخص می‌کند که کاربر به واحد-شیفت داده شده دسترسی دارد.
+func CanAccessDepartmentShift(user *core.User, deptShift string) bool {
+	for _, d := range AccessibleDepartmentShifts(user) {
+		if d == deptShift {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize بررسی می‌کند که کاربر توانمندی داده شده را (در صورت مشخص بودن deptShift، برای آن واحد-شیفت) دارد.
+func Authorize(user *core.User, capability Capability, deptShift string) error {
+	if user == nil {
+		return fmt.Errorf("کاربری وارد نشده است")
+	}
+	if !Can(user, capability) {
+		return fmt.Errorf("کاربر '%s' مجوز '%s' را ندارد", user.Username, capability)
+	}
+	if deptShift != "" && !CanAccessDepartmentShift(user, deptShift) {
+		return fmt.Errorf("کاربر '%s' به واحد '%s' دسترسی ندارد", user.Username, deptShift)
+	}
+	return nil
+}
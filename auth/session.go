@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"overtime_go/core"
+)
+
+// SessionTokenLifetime مدت اعتبار توکن "مرا به خاطر بسپار" است.
+const SessionTokenLifetime = 14 * 24 * time.Hour
+
+// ErrInvalidSessionToken برای توکن نامعتبر، منقضی یا باطل شده برگردانده می‌شود.
+var ErrInvalidSessionToken = errors.New("نشست ذخیره شده معتبر نیست؛ لطفا دوباره رمز عبور را وارد کنید")
+
+// sessionClaims محتوای امضا شده توکن نشست است. Generation باید با SessionGeneration کاربر برابر باشد.
+type sessionClaims struct {
+	Username   string `json:"u"`
+	Generation int    `json:"g"`
+	ExpiresAt  int64  `json:"exp"`
+}
+
+// sessionSigningKey کلید امضای توکن‌ها را از کلید نصب مشتق می‌کند تا با کلید رمزنگاری فایل کاربران یکسان نباشد.
+func sessionSigningKey() ([]byte, error) {
+	return DerivedKey("overtime-session-token-v1")
+}
+
+func signSessionPayload(payload string) (string, error) {
+	key, err := sessionSigningKey()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// IssueSessionToken یک توکن نشست محلی امضا شده با کلید نصب برای کاربر صادر می‌کند.
+// توکن به جای رمز عبور در تنظیمات برنامه ذخیره می‌شود.
+func IssueSessionToken(username string) (string, error) {
+	user, err := lookupUser(username)
+	if err != nil {
+		return "", err
+	}
+	claims := sessionClaims{
+		Username:   user.Username,
+		Generation: user.SessionGeneration,
+		ExpiresAt:  time.Now().Add(SessionTokenLifetime).Unix(),
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("خطا در ساخت توکن نشست: %w", err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(claimsJSON)
+	signature, err := signSessionPayload(payload)
+	if err != nil {
+		return "", fmt.Errorf("خطا در امضای توکن نشست: %w", err)
+	}
+	return payload + "." + signature, nil
+}
+
+// AuthenticateSessionToken توکن نشست را بررسی می‌کند و کاربر مربوط را برمی‌گرداند.
+// توکن منقضی، با امضای نادرست، باطل شده (تغییر SessionGeneration) یا متعلق به کاربر غیرفعال پذیرفته نمی‌شود.
+func AuthenticateSessionToken(token string) (*core.User, error) {
+	payload, signature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, ErrInvalidSessionToken
+	}
+	expected, err := signSessionPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, ErrInvalidSessionToken
+	}
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidSessionToken
+	}
+	var claims sessionClaims
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return nil, ErrInvalidSessionToken
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, ErrInvalidSessionToken
+	}
+	user, exists := ProcessedUsers[claims.Username]
+	if !exists || user.Disabled || user.MustChangePassword || user.SessionGeneration != claims.Generation {
+		return nil, ErrInvalidSessionToken
+	}
+	return &user, nil
+}
+
+// RevokeSessions همه توکن‌های نشست صادر شده برای کاربر را باطل می‌کند (مثلا هنگام خروج از حساب).
+func RevokeSessions(username string) error {
+	user, err := lookupUser(username)
+	if err != nil {
+		return err
+	}
+	user.SessionGeneration++
+	return saveUserChange(username, &user)
+}
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"overtime_go/core"
+)
+
+const (
+	usersFilename      = "users.dat"
+	userImportFilename = "users_import.json"
+	userStoreVersion   = 1
+)
+
+// userStoreFile قالب JSON فایل کاربران پیش از رمزنگاری است.
+type userStoreFile struct {
+	Version int         `json:"version"`
+	Users   []core.User `json:"users"`
+}
+
+func encryptWithInstallKey(plain []byte) ([]byte, error) {
+	key, err := installKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func decryptWithInstallKey(sealed []byte) ([]byte, error) {
+	key, err := installKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("داده رمزنگاری شده کوتاه‌تر از حد انتظار است")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// loadUserStore فایل رمزنگاری شده کاربران را می‌خواند. اگر فایل وجود نداشته باشد خطای os.ErrNotExist برمی‌گردد.
+func loadUserStore() (map[string]core.User, error) {
+	filePath := appFilePath(usersFilename)
+	sealed, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("خطا در خواندن فایل کاربران '%s': %w", filePath, err)
+	}
+	plain, err := decryptWithInstallKey(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("خطا در رمزگشایی فایل کاربران '%s' (فایل یا %s تغییر کرده است): %w", filePath, installKeyFilename, err)
+	}
+
+	var store userStoreFile
+	if err := json.Unmarshal(plain, &store); err != nil {
+		return nil, fmt.Errorf("خطا در پارس کردن فایل کاربران '%s': %w", filePath, err)
+	}
+	users := make(map[string]core.User, len(store.Users))
+	for _, u := range store.Users {
+		if u.Username == "" {
+			continue
+		}
+		users[u.Username] = u
+	}
+	return users, nil
+}
+
+// SaveUsers کاربران موجود در ProcessedUsers را به صورت رمزنگاری شده در فایل کاربران ذخیره می‌کند.
+func SaveUsers() error {
+	store := userStoreFile{Version: userStoreVersion}
+	for _, u := range ProcessedUsers {
+		store.Users = append(store.Users, u)
+	}
+	sort.Slice(store.Users, func(i, j int) bool { return store.Users[i].Username < store.Users[j].Username })
+
+	plain, err := json.Marshal(store)
+	if err != nil {
+		return fmt.Errorf("خطا در تبدیل کاربران به JSON: %w", err)
+	}
+	sealed, err := encryptWithInstallKey(plain)
+	if err != nil {
+		return fmt.Errorf("خطا در رمزنگاری فایل کاربران: %w", err)
+	}
+
+	filePath := appFilePath(usersFilename)
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, sealed, 0600); err != nil {
+		return fmt.Errorf("خطا در نوشتن فایل کاربران '%s': %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("خطا در جایگزینی فایل کاربران '%s': %w", filePath, err)
+	}
+	return nil
+}
+
+// userImportEntry یک ردیف از فایل users_import.json است. فیلدهای خالی (و Disabled نیامده) مقدار فعلی کاربر را حفظ می‌کنند.
+// کاربری که رمزش از این فایل تعیین شود، در ورود بعدی ملزم به تغییر آن است.
+type userImportEntry struct {
+	Username   string `json:"username"`
+	Password   string `json:"password,omitempty"`
+	Role       string `json:"role,omitempty"`
+	Department string `json:"department,omitempty"`
+	Disabled   *bool  `json:"disabled,omitempty"`
+}
+
+// applyUserImportFile فایل users_import.json کنار برنامه را (در صورت وجود) در فایل کاربران ادغام
+// و سپس حذف می‌کند تا رمزهای متنی روی دیسک باقی نمانند.
+func applyUserImportFile() error {
+	importPath := appFilePath(userImportFilename)
+	fileData, err := os.ReadFile(importPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var entries []userImportEntry
+	if err := json.Unmarshal(fileData, &entries); err != nil {
+		return fmt.Errorf("خطا در پارس کردن '%s': %w", importPath, err)
+	}
+	for _, entry := range entries {
+		username := strings.TrimSpace(entry.Username)
+		if username == "" {
+			continue
+		}
+		previous, exists := ProcessedUsers[username]
+		user := previous
+		if !exists {
+			if entry.Password == "" {
+				fmt.Printf("هشدار: کاربر جدید '%s' در فایل %s رمز عبور ندارد و نادیده گرفته شد.\n", username, userImportFilename)
+				continue
+			}
+			user = core.User{Username: username}
+		}
+		if entry.Password != "" {
+			hashed, err := HashPassword(entry.Password)
+			if err != nil {
+				return err
+			}
+			user.Password = hashed
+			user.MustChangePassword = true
+			user.SessionGeneration++
+		}
+		if entry.Role != "" {
+			user.Role = entry.Role
+		}
+		if entry.Department != "" {
+			user.Department = entry.Department
+		}
+		if !exists || entry.Role != "" || entry.Department != "" {
+			if err := ValidateRoleAndDepartment(user.Role, user.Department); err != nil {
+				fmt.Printf("هشدار: ردیف کاربر '%s' در فایل %s نادیده گرفته شد: %v\n", username, userImportFilename, err)
+				continue
+			}
+		}
+		if entry.Disabled != nil {
+			if *entry.Disabled && !user.Disabled {
+				user.SessionGeneration++
+			}
+			user.Disabled = *entry.Disabled
+		}
+		if exists && isActiveUserManager(previous) && !isActiveUserManager(user) && activeUserManagerCount(username) == 0 {
+			fmt.Printf("هشدار: ردیف کاربر '%s' در فایل %s نادیده گرفته شد؛ حداقل یک کاربر فعال با مجوز مدیریت کاربران باید باقی بماند.\n", username, userImportFilename)
+			continue
+		}
+		ProcessedUsers[username] = user
+	}
+
+	if err := SaveUsers(); err != nil {
+		return err
+	}
+	if err := os.Remove(importPath); err != nil {
+		fmt.Printf("هشدار: فایل '%s' پس از اعمال حذف نشد: %v\n", importPath, err)
+	}
+	fmt.Printf("%d ردیف از فایل %s در فایل کاربران اعمال شد.\n", len(entries), userImportFilename)
+	return nil
+}
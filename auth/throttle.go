@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	loginAttemptsFilename = "login_attempts.json"
+	loginPolicyFilename   = "login_policy.json"
+)
+
+// LockoutPolicy تنظیمات محدودسازی تلاش‌های ناموفق ورود است و از فایل login_policy.json
+// (کنار فایل اجرایی، در صورت وجود) قابل تغییر است.
+type LockoutPolicy struct {
+	MaxFailures       int `json:"max_failures"`        // تعداد تلاش ناموفق تا قفل موقت حساب
+	LockoutMinutes    int `json:"lockout_minutes"`     // مدت قفل موقت
+	BaseDelaySeconds  int `json:"base_delay_seconds"`  // تأخیر پس از اولین تلاش ناموفق؛ با هر تلاش دو برابر می‌شود
+	MaxDelaySeconds   int `json:"max_delay_seconds"`   // سقف تأخیر بین دو تلاش
+	ResetAfterMinutes int `json:"reset_after_minutes"` // پس از این مدت بدون تلاش ناموفق، شمارنده صفر می‌شود
+}
+
+// DefaultLockoutPolicy تنظیمات پیش‌فرض محدودسازی ورود است.
+var DefaultLockoutPolicy = LockoutPolicy{
+	MaxFailures:       5,
+	LockoutMinutes:    15,
+	BaseDelaySeconds:  1,
+	MaxDelaySeconds:   30,
+	ResetAfterMinutes: 60,
+}
+
+// loginAttempt وضعیت تلاش‌های ناموفق یک نام کاربری است.
+type loginAttempt struct {
+	Failures      int       `json:"failures"`
+	LastFailure   time.Time `json:"last_failure"`
+	NextAllowedAt time.Time `json:"next_allowed_at"`
+	LockedUntil   time.Time `json:"locked_until"`
+}
+
+var (
+	loginPolicy   *LockoutPolicy
+	loginAttempts map[string]loginAttempt
+)
+
+// LoginThrottledError وقتی برگردانده می‌شود که ورود به دلیل تلاش‌های ناموفق قبلی موقتا مجاز نیست.
+type LoginThrottledError struct {
+	Username string
+	RetryAt  time.Time
+	Locked   bool // true یعنی حساب تا RetryAt قفل شده است؛ false یعنی فقط باید کمی صبر کرد
+}
+
+func (e *LoginThrottledError) Error() string {
+	wait := time.Until(e.RetryAt).Round(time.Second)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	if e.Locked {
+		return fmt.Sprintf("به دلیل تلاش‌های ناموفق متعدد، حساب '%s' تا %s قفل شده است. برای رفع قفل با مدیر سیستم تماس بگیرید.", e.Username, formatWait(wait))
+	}
+	return fmt.Sprintf("تلاش ناموفق قبلی ثبت شده است. لطفا %s دیگر دوباره تلاش کنید.", formatWait(wait))
+}
+
+func formatWait(d time.Duration) string {
+	if d >= time.Minute {
+		return fmt.Sprintf("%d دقیقه", int((d+time.Minute-1)/time.Minute))
+	}
+	return fmt.Sprintf("%d ثانیه", int(d/time.Second))
+}
+
+// currentLockoutPolicy تنظیمات محدودسازی را (یک بار) از فایل login_policy.json می‌خواند؛ مقادیر نامعتبر یا
+// نبود فایل به معنای استفاده از DefaultLockoutPolicy است.
+func currentLockoutPolicy() LockoutPolicy {
+	if loginPolicy != nil {
+		return *loginPolicy
+	}
+	policy := DefaultLockoutPolicy
+	policyPath := appFilePath(loginPolicyFilename)
+	if fileData, err := os.ReadFile(policyPath); err == nil {
+		var fromFile LockoutPolicy
+		if err := json.Unmarshal(fileData, &fromFile); err != nil {
+			fmt.Printf("هشدار: خطا در پارس کردن '%s': %v. استفاده از تنظیمات پیش‌فرض.\n", policyPath, err)
+		} else {
+			if fromFile.MaxFailures > 0 {
+				policy.MaxFailures = fromFile.MaxFailures
+			}
+			if fromFile.LockoutMinutes > 0 {
+				policy.LockoutMinutes = fromFile.LockoutMinutes
+			}
+			if fromFile.BaseDelaySeconds > 0 {
+				policy.BaseDelaySeconds = fromFile.BaseDelaySeconds
+			}
+			if fromFile.MaxDelaySeconds > 0 {
+				policy.MaxDelaySeconds = fromFile.MaxDelaySeconds
+			}
+			if fromFile.ResetAfterMinutes > 0 {
+				policy.ResetAfterMinutes = fromFile.ResetAfterMinutes
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		fmt.Printf("هشدار: خطا در خواندن '%s': %v. استفاده از تنظیمات پیش‌فرض.\n", policyPath, err)
+	}
+	loginPolicy = &policy
+	return policy
+}
+
+// loadLoginAttempts وضعیت تلاش‌های ناموفق را از فایل login_attempts.json می‌خواند تا با راه‌اندازی مجدد برنامه صفر نشود.
+func loadLoginAttempts() map[string]loginAttempt {
+	if loginAttempts != nil {
+		return loginAttempts
+	}
+	loginAttempts = make(map[string]loginAttempt)
+	filePath := appFilePath(loginAttemptsFilename)
+	fileData, err := os.ReadFile(filePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("هشدار: خطا در خواندن '%s': %v\n", filePath, err)
+		}
+		return loginAttempts
+	}
+	if err := json.Unmarshal(fileData, &loginAttempts); err != nil {
+		fmt.Printf("هشدار: خطا در پارس کردن '%s': %v\n", filePath, err)
+		loginAttempts = make(map[string]loginAttempt)
+	}
+	return loginAttempts
+}
+
+// saveLoginAttempts وضعیت تلاش‌ها را ذخیره می‌کند و ردیف‌های منقضی شده را کنار می‌گذارد.
+func saveLoginAttempts() {
+	policy := currentLockoutPolicy()
+	now := time.Now()
+	for username, attempt := range loginAttempts {
+		if attemptExpired(attempt, policy, now) {
+			delete(loginAttempts, username)
+		}
+	}
+
+	filePath := appFilePath(loginAttemptsFilename)
+	fileData, err := json.MarshalIndent(loginAttempts, "", "  ")
+	if err != nil {
+		fmt.Printf("هشدار: خطا در تبدیل وضعیت تلاش‌های ورود به JSON: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(filePath, fileData, 0600); err != nil {
+		fmt.Printf("هشدار: خطا در ذخیره '%s': %v\n", filePath, err)
+	}
+}
+
+func attemptExpired(attempt loginAttempt, policy LockoutPolicy, now time.Time) bool {
+	return now.After(attempt.LockedUntil) &&
+		now.Sub(attempt.LastFailure) > time.Duration(policy.ResetAfterMinutes)*time.Minute
+}
+
+// checkLoginAllowed بررسی می‌کند که تلاش ورود برای نام کاربری در این لحظه مجاز است یا نه.
+func checkLoginAllowed(username string) error {
+	attempt, exists := loadLoginAttempts()[username]
+	if !exists {
+		return nil
+	}
+	now := time.Now()
+	if now.Before(attempt.LockedUntil) {
+		return &LoginThrottledError{Username: username, RetryAt: attempt.LockedUntil, Locked: true}
+	}
+	if now.Before(attempt.NextAllowedAt) {
+		return &LoginThrottledError{Username: username, RetryAt: attempt.NextAllowedAt}
+	}
+	return nil
+}
+
+// recordLoginFailure یک تلاش ناموفق را ثبت می‌کند. تأخیر تلاش بعدی به صورت نمایی افزایش می‌یابد و پس از
+// MaxFailures تلاش، حساب به مدت LockoutMinutes قفل می‌شود.
+func recordLoginFailure(username string) {
+	attempts := loadLoginAttempts()
+	policy := currentLockoutPolicy()
+	now := time.Now()
+
+	attempt := attempts[username]
+	if attemptExpired(attempt, policy, now) {
+		attempt = loginAttempt{}
+	}
+	attempt.Failures++
+	attempt.LastFailure = now
+
+	delay := time.Duration(policy.BaseDelaySeconds) * time.Second
+	maxDelay := time.Duration(policy.MaxDelaySeconds) * time.Second
+	for i := 1; i < attempt.Failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	attempt.NextAllowedAt = now.Add(delay)
+
+	if attempt.Failures >= policy.MaxFailures {
+		attempt.LockedUntil = now.Add(time.Duration(policy.LockoutMinutes) * time.Minute)
+		attempt.Failures = 0
+		fmt.Printf("هشدار: حساب '%s' پس از %d تلاش ناموفق تا %s قفل شد.\n", username, policy.MaxFailures, attempt.LockedUntil.Format("15:04:05"))
+	}
+	attempts[username] = attempt
+	saveLoginAttempts()
+}
+
+// clearLoginFailures پس از ورود موفق، شمارنده تلاش‌های ناموفق را پاک می‌کند.
+func clearLoginFailures(username string) {
+	attempts := loadLoginAttempts()
+	if _, exists := attempts[username]; !exists {
+		return
+	}
+	delete(attempts, username)
+	saveLoginAttempts()
+}
+
+// LockedUntil زمان پایان قفل حساب را برمی‌گرداند؛ false یعنی حساب در حال حاضر قفل نیست.
+func LockedUntil(username string) (time.Time, bool) {
+	attempt, exists := loadLoginAttempts()[username]
+	if !exists || !time.Now().Before(attempt.LockedUntil) {
+		return time.Time{}, false
+	}
+	return attempt.LockedUntil, true
+}
+
+// UnlockUser قفل حساب و شمارنده تلاش‌های ناموفق یک کاربر را توسط مدیر پاک می‌کند.
+func UnlockUser(username string) error {
+	if _, err := lookupUser(username); err != nil {
+		return err
+	}
+	clearLoginFailures(username)
+	return nil
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"overtime_go/core"
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,32}$`)
+
+// ListUsers فهرست کاربران را به ترتیب نام کاربری برمی‌گرداند.
+func ListUsers() []core.User {
+	users := make([]core.User, 0, len(ProcessedUsers))
+	for _, u := range ProcessedUsers {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users
+}
+
+// ValidateRoleAndDepartment نقش و دپارتمان یک کاربر را بررسی می‌کند.
+func ValidateRoleAndDepartment(role, department string) error {
+	if !IsValidRole(role) {
+		return fmt.Errorf("نقش '%s' تعریف نشده است", role)
+	}
+	if !core.IsValidUserDepartment(department) {
+		return fmt.Errorf("واحد '%s' در ساختار سازمانی یا گروه‌های ویژه تعریف نشده است", department)
+	}
+	if department == core.AllDepartments && !RoleHasCapability(role, CapAllDepartments) {
+		return fmt.Errorf("برای نقش '%s' باید یک واحد یا گروه مشخص انتخاب شود", RoleDisplayName(role))
+	}
+	return nil
+}
+
+// isActiveUserManager مشخص می‌کند که کاربر فعال است و توانمندی مدیریت کاربران را دارد.
+func isActiveUserManager(u core.User) bool {
+	return !u.Disabled && RoleHasCapability(u.Role, CapManageUsers)
+}
+
+// activeUserManagerCount تعداد کاربران فعال دارای توانمندی مدیریت کاربران را برمی‌گرداند، به جز کاربر exclude.
+func activeUserManagerCount(exclude string) int {
+	count := 0
+	for username, u := range ProcessedUsers {
+		if username != exclude && isActiveUserManager(u) {
+			count++
+		}
+	}
+	return count
+}
+
+// saveUserChange تغییر یک کاربر را اعمال و ذخیره می‌کند؛ در صورت خطا در ذخیره، تغییر برگردانده می‌شود.
+func saveUserChange(username string, updated *core.User) error {
+	previous, existed := ProcessedUsers[username]
+	if updated == nil {
+		delete(ProcessedUsers, username)
+	} else {
+		ProcessedUsers[username] = *updated
+	}
+	if err := SaveUsers(); err != nil {
+		if existed {
+			ProcessedUsers[username] = previous
+		} else {
+			delete(ProcessedUsers, username)
+		}
+		return err
+	}
+	return nil
+}
+
+func lookupUser(username string) (core.User, error) {
+	user, exists := ProcessedUsers[username]
+	if !exists {
+		return core.User{}, fmt.Errorf("کاربر '%s' یافت نشد", username)
+	}
+	return user, nil
+}
+
+// CreateUser کاربر جدیدی با رمز عبور، نقش و دپارتمان داده شده ایجاد می‌کند؛ کاربر در اولین ورود ملزم به تغییر رمز است.
+func CreateUser(username, password, role, department string) error {
+	username = strings.TrimSpace(username)
+	if !usernamePattern.MatchString(username) {
+		return fmt.Errorf("نام کاربری باید 3 تا 32 نویسه از حروف لاتین، ارقام، نقطه، خط تیره یا زیرخط باشد")
+	}
+	if _, exists := ProcessedUsers[username]; exists {
+		return fmt.Errorf("کاربر '%s' از قبل وجود دارد", username)
+	}
+	if err := ValidatePasswordPolicy(password); err != nil {
+		return err
+	}
+	if err := ValidateRoleAndDepartment(role, department); err != nil {
+		return err
+	}
+	hashed, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return saveUserChange(username, &core.User{
+		Username:           username,
+		Password:           hashed,
+		Role:               role,
+		Department:         department,
+		MustChangePassword: true,
+	})
+}
+
+// UpdateUser نقش و دپارتمان یک کاربر را تغییر می‌دهد.
+func UpdateUser(username, role, department string) error {
+	user, err := lookupUser(username)
+	if err != nil {
+		return err
+	}
+	if err := ValidateRoleAndDepartment(role, department); err != nil {
+		return err
+	}
+	if isActiveUserManager(user) && !RoleHasCapability(role, CapManageUsers) && activeUserManagerCount(username) == 0 {
+		return fmt.Errorf("حداقل یک کاربر فعال با مجوز مدیریت کاربران باید باقی بماند")
+	}
+	user.Role = role
+	user.Department = department
+	return saveUserChange(username, &user)
+}
+
+// ResetPassword رمز عبور یک کاربر را توسط مدیر تغییر می‌دهد و قفل حساب را برمی‌دارد؛ کاربر در ورود بعدی ملزم به تغییر آن است.
+func ResetPassword(username, newPassword string) error {
+	user, err := lookupUser(username)
+	if err != nil {
+		return err
+	}
+	if err := ValidatePasswordPolicy(newPassword); err != nil {
+		return err
+	}
+	hashed, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	user.Password = hashed
+	user.MustChangePassword = true
+	user.SessionGeneration++
+	if err := saveUserChange(username, &user); err != nil {
+		return err
+	}
+	clearLoginFailures(username)
+	return nil
+}
+
+// SetUserDisabled حساب یک کاربر را فعال یا غیرفعال می‌کند.
+func SetUserDisabled(username string, disabled bool) error {
+	user, err := lookupUser(username)
+	if err != nil {
+		return err
+	}
+	if disabled && isActiveUserManager(user) && activeUserManagerCount(username) == 0 {
+		return fmt.Errorf("حداقل یک کاربر فعال با مجوز مدیریت کاربران باید باقی بماند")
+	}
+	user.Disabled = disabled
+	if disabled {
+		user.SessionGeneration++
+	}
+	return saveUserChange(username, &user)
+}
+
+// DeleteUser یک کاربر را حذف می‌کند.
+func DeleteUser(username string) error {
+	user, err := lookupUser(username)
+	if err != nil {
+		return err
+	}
+	if isActiveUserManager(user) && activeUserManagerCount(username) == 0 {
+		return fmt.Errorf("حداقل یک کاربر فعال با مجوز مدیریت کاربران باید باقی بماند")
+	}
+	return saveUserChange(username, nil)
+}
+
+// SetDepartmentShiftAccess فهرست صریح واحد-شیفت‌های قابل دسترسی کاربر را تعیین می‌کند؛
+// فهرست خالی یعنی دسترسی بر اساس دپارتمان کاربر.
+func SetDepartmentShiftAccess(username string, deptShifts []string) error {
+	user, err := lookupUser(username)
+	if err != nil {
+		return err
+	}
+	var access []string
+	for _, deptShift := range deptShifts {
+		if !isManageableDepartmentShift(deptShift) {
+			return fmt.Errorf("واحد-شیفت '%s' در ساختار سازمانی تعریف نشده است", deptShift)
+		}
+		access = append(access, deptShift)
+	}
+	sort.Strings(access)
+	user.DepartmentShiftAccess = access
+	return saveUserChange(username, &user)
+}
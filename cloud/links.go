@@ -18,10 +18,11 @@ import (
 const cloudLinksFilename = "cloud_links.json"
 
 var (
-	loadedCloudLinks map[string]string
+	loadedCloudLinks map[string]core.CloudLink
 )
 
-func LoadCloudLinks() map[string]string {
+// LoadCloudLinks لینک دانلود و قالب فایل هر واحد را از cloud_links.json (یا در نبود آن، از پیش‌فرض‌های جاسازی شده) برمی‌گرداند.
+func LoadCloudLinks() map[string]core.CloudLink {
 	if loadedCloudLinks != nil {
 		return loadedCloudLinks
 	}
@@ -38,7 +39,7 @@ func LoadCloudLinks() map[string]string {
 	}
 
 	fileData, err := ioutil.ReadFile(linksFilePath)
-	currentLinksFromFile := make(map[string]string)
+	currentLinksFromFile := make(map[string]core.CloudLink)
 	useEmbeddedDefaults := false
 
 	if err != nil {
@@ -53,18 +54,18 @@ func LoadCloudLinks() map[string]string {
 	}
 
 	embeddedDefaults := core.GetDefaultCloudLinks()
-	finalResolvedLinks := make(map[string]string)
+	finalResolvedLinks := make(map[string]core.CloudLink)
 
 	for _, deptShift := range core.ManageableDepartments {
 		linkFromFile, foundInFile := currentLinksFromFile[deptShift]
 		linkFromEmbedded, foundInEmbedded := embeddedDefaults[deptShift]
 
-		if !useEmbeddedDefaults && foundInFile && strings.TrimSpace(linkFromFile) != "" {
+		if !useEmbeddedDefaults && foundInFile && strings.TrimSpace(linkFromFile.URL) != "" {
 			finalResolvedLinks[deptShift] = linkFromFile
-		} else if foundInEmbedded && strings.TrimSpace(linkFromEmbedded) != "" {
+		} else if foundInEmbedded && strings.TrimSpace(linkFromEmbedded.URL) != "" {
 			finalResolvedLinks[deptShift] = linkFromEmbedded
 		} else {
-			finalResolvedLinks[deptShift] = ""
+			finalResolvedLinks[deptShift] = core.CloudLink{}
 			if deptShift != "" {
 				// fmt.Printf("هشدار: هیچ لینکی (نه در فایل، نه پیش‌فرض) برای واحد '%s' یافت نشد.\n", deptShift)
 			}
@@ -74,7 +75,8 @@ func LoadCloudLinks() map[string]string {
 	return loadedCloudLinks
 }
 
-func SaveCloudLinks(linksToSave map[string]string) error {
+// SaveCloudLinks لینک‌ها و قالب فایل واحدها را در cloud_links.json ذخیره می‌کند. لینک بدون قالب به شکل رشته نوشته می‌شود.
+func SaveCloudLinks(linksToSave map[string]core.CloudLink) error {
 	appDir, err := GetExecutableDir() // فراخوانی مستقیم تابع از همین پکیج cloud
 	var linksFilePath string
 	// ... (بقیه کد SaveCloudLinks بدون تغییر عمده، فقط فراخوانی GetExecutableDir اصلاح می‌شود) ...
@@ -96,7 +98,7 @@ func SaveCloudLinks(linksToSave map[string]string) error {
 		return fmt.Errorf("خطا در نوشتن فایل cloud_links.json در مسیر '%s': %w", linksFilePath, err)
 	}
 
-	newLoadedLinks := make(map[string]string)
+	newLoadedLinks := make(map[string]core.CloudLink)
 	for k, v := range linksToSave {
 		newLoadedLinks[k] = v
 	}
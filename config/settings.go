@@ -5,27 +5,56 @@ import (
 )
 
 const (
-	prefUsername   = "username"
-	prefPassword   = "password"
-	prefRememberMe = "remember_me"
+	prefUsername     = "username"
+	prefSessionToken = "session_token"
+	prefRememberMe   = "remember_me"
+	prefIdleTimeout  = "idle_timeout_minutes"
+	prefAuditSeq     = "audit_head_seq"
+	prefAuditHash    = "audit_head_hash"
+
+	// prefLegacyPassword رمز متنی ذخیره شده در نسخه‌های قبلی است که هنگام بارگذاری حذف می‌شود.
+	prefLegacyPassword = "password"
 )
 
+// DefaultIdleTimeoutMinutes مدت پیش‌فرض عدم فعالیت تا خروج خودکار از حساب است.
+const DefaultIdleTimeoutMinutes = 15
+
+// AppSettings تنظیمات ذخیره شده ورود است. به جای رمز عبور، فقط توکن نشست امضا شده نگهداری می‌شود.
 type AppSettings struct {
-	Username   string
-	Password   string
-	RememberMe bool
+	Username     string
+	SessionToken string
+	RememberMe   bool
+	// IdleTimeoutMinutes مدت عدم فعالیت تا خروج خودکار؛ صفر یا منفی یعنی غیرفعال.
+	IdleTimeoutMinutes int
 }
 
 func LoadSettings(app fyne.App) AppSettings {
+	if app.Preferences().String(prefLegacyPassword) != "" {
+		app.Preferences().RemoveValue(prefLegacyPassword)
+	}
 	return AppSettings{
-		Username:   app.Preferences().StringWithFallback(prefUsername, ""),
-		Password:   app.Preferences().StringWithFallback(prefPassword, ""),
-		RememberMe: app.Preferences().BoolWithFallback(prefRememberMe, false),
+		Username:     app.Preferences().StringWithFallback(prefUsername, ""),
+		SessionToken: app.Preferences().StringWithFallback(prefSessionToken, ""),
+		RememberMe:   app.Preferences().BoolWithFallback(prefRememberMe, false),
+
+		IdleTimeoutMinutes: app.Preferences().IntWithFallback(prefIdleTimeout, DefaultIdleTimeoutMinutes),
 	}
 }
 
 func SaveSettings(app fyne.App, settings AppSettings) {
 	app.Preferences().SetString(prefUsername, settings.Username)
-	app.Preferences().SetString(prefPassword, settings.Password)
+	app.Preferences().SetString(prefSessionToken, settings.SessionToken)
 	app.Preferences().SetBool(prefRememberMe, settings.RememberMe)
+	app.Preferences().SetInt(prefIdleTimeout, settings.IdleTimeoutMinutes)
+}
+
+// LoadAuditHead شماره و هش آخرین ردیف گزارش حسابرسی را که جدا از فایل گزارش ذخیره شده برمی‌گرداند.
+func LoadAuditHead(app fyne.App) (int, string) {
+	return app.Preferences().IntWithFallback(prefAuditSeq, 0), app.Preferences().StringWithFallback(prefAuditHash, "")
+}
+
+// SaveAuditHead شماره و هش آخرین ردیف گزارش حسابرسی را ذخیره می‌کند.
+func SaveAuditHead(app fyne.App, seq int, hash string) {
+	app.Preferences().SetInt(prefAuditSeq, seq)
+	app.Preferences().SetString(prefAuditHash, hash)
 }
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"overtime_go/utils"
+)
+
+const columnHeadersFilename = "column_headers.json"
+
+// کلید ستون‌های شناخته شده فایل پرسنل
+const (
+	ColumnDepartment     = "department"
+	ColumnName           = "name"
+	ColumnID             = "id"
+	ColumnWeight         = "weight"
+	ColumnProductionDays = "production_days"
+	ColumnSeniority      = "seniority"
+	ColumnMinHours       = "min_hours"
+	ColumnMaxHours       = "max_hours"
+)
+
+// ColumnDefinition یک ستون فایل پرسنل و عناوین قابل قبول (مترادف‌های) هدر آن است. DefaultIndex ستون
+// پیش‌فرض (A=0) برای فایل‌هایی است که هیچ هدر شناخته شده‌ای ندارند.
+type ColumnDefinition struct {
+	Key          string   `json:"key"`
+	Title        string   `json:"title"`
+	Required     bool     `json:"required"`
+	DefaultIndex int      `json:"default_index"`
+	Synonyms     []string `json:"synonyms"`
+}
+
+type columnHeadersFile struct {
+	Columns []ColumnDefinition `json:"columns"`
+}
+
+var employeeColumns []ColumnDefinition
+
+// InitializeColumnHeaders تعریف ستون‌ها را از فایل پیش‌فرض جاسازی شده می‌خواند. مترادف‌های فایل
+// column_headers.json کنار برنامه (در صورت وجود) به مترادف‌های پیش‌فرض همان ستون اضافه می‌شوند.
+func InitializeColumnHeaders(defaultJSON []byte) error {
+	var defaults columnHeadersFile
+	if err := json.Unmarshal(defaultJSON, &defaults); err != nil {
+		return fmt.Errorf("خطا در پارس کردن عناوین پیش‌فرض ستون‌ها: %w", err)
+	}
+	employeeColumns = defaults.Columns
+
+	appDir, err := utils.GetExecutableDir()
+	if err != nil {
+		appDir, _ = os.Getwd()
+	}
+	overridePath := filepath.Join(appDir, columnHeadersFilename)
+	fileData, err := os.ReadFile(overridePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("هشدار: خطا در خواندن '%s': %v. استفاده از عناوین پیش‌فرض ستون‌ها.\n", overridePath, err)
+		}
+		return nil
+	}
+	var overrides columnHeadersFile
+	if err := json.Unmarshal(fileData, &overrides); err != nil {
+		fmt.Printf("هشدار: خطا در پارس کردن '%s': %v. استفاده از عناوین پیش‌فرض ستون‌ها.\n", overridePath, err)
+		return nil
+	}
+	for _, override := range overrides.Columns {
+		for i := range employeeColumns {
+			if employeeColumns[i].Key == override.Key {
+				employeeColumns[i].Synonyms = append(employeeColumns[i].Synonyms, override.Synonyms...)
+			}
+		}
+	}
+	fmt.Printf("عناوین ستون‌ها از فایل '%s' اعمال شد.\n", overridePath)
+	return nil
+}
+
+// EmployeeColumns تعریف ستون‌های فایل پرسنل را برمی‌گرداند.
+func EmployeeColumns() []ColumnDefinition {
+	return employeeColumns
+}
+
+// NormalizeHeader متن هدر را برای مقایسه یکسان می‌کند (نویسه‌های عربی، فاصله‌های اضافی، نیم‌فاصله و حروف بزرگ).
+func NormalizeHeader(s string) string {
+	s = strings.ReplaceAll(NormalizePersianText(s), "\u200c", " ")
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,31 @@
+package core
+
+// ImportedDepartment اطلاعات یک واحد است که از فایل اکسل (ورود اطلاعات ادمین یا به‌روزرسانی از سرور) خوانده شده است.
+type ImportedDepartment struct {
+	TotalHours     int
+	ProductionDays int
+	Period         Period // مقدار صفر یعنی دوره در فایل نامعتبر یا خالی بوده است
+	Employees      []Employee
+}
+
+// ResolvedPeriod دوره فایل را برمی‌گرداند؛ اگر دوره فایل خالی باشد، دوره جاری سیستم.
+func (imp ImportedDepartment) ResolvedPeriod() Period {
+	if imp.Period.IsZero() {
+		return CurrentPeriod()
+	}
+	return imp.Period
+}
+
+// ApplyImport سرانه، روزهای تولید، دوره و لیست پرسنل خوانده شده را روی داده واحد اعمال می‌کند و دوره را
+// به همه پرسنل منتقل می‌کند تا خروجی اکسل ماه فایل را نشان دهد. لیست پرسنل imported تغییر نمی‌کند.
+func ApplyImport(data *DepartmentData, imported ImportedDepartment) {
+	period := imported.ResolvedPeriod()
+	data.TotalHours = imported.TotalHours
+	data.ProductionDays = imported.ProductionDays
+	data.Period = period
+	data.Employees = make([]Employee, len(imported.Employees))
+	copy(data.Employees, imported.Employees)
+	for i := range data.Employees {
+		data.Employees[i].Period = period
+	}
+}
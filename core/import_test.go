@@ -0,0 +1,75 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyImportPropagatesPeriod(t *testing.T) {
+	period := Period{Year: 1404, Month: 2}
+	imported := ImportedDepartment{
+		TotalHours:     120,
+		ProductionDays: 22,
+		Period:         period,
+		Employees: []Employee{
+			{Name: "علی", ID: "1", Period: Period{Year: 1403, Month: 12}},
+			{Name: "رضا", ID: "2"},
+		},
+	}
+	original := append([]Employee(nil), imported.Employees...)
+	data := DepartmentData{
+		DepartmentShiftName: "انبار - شیفتی",
+		Period:              Period{Year: 1403, Month: 11},
+		Employees:           []Employee{{Name: "قدیمی", ID: "9"}},
+	}
+
+	ApplyImport(&data, imported)
+
+	if data.TotalHours != 120 || data.ProductionDays != 22 {
+		t.Errorf("TotalHours, ProductionDays = %d, %d; want 120, 22", data.TotalHours, data.ProductionDays)
+	}
+	if data.Period != period {
+		t.Errorf("data.Period = %v, want %v", data.Period, period)
+	}
+	if len(data.Employees) != len(original) {
+		t.Fatalf("len(data.Employees) = %d, want %d", len(data.Employees), len(original))
+	}
+	for i, emp := range data.Employees {
+		if emp.Period != period {
+			t.Errorf("Employees[%d].Period = %v, want %v", i, emp.Period, period)
+		}
+		if emp.Name != original[i].Name || emp.ID != original[i].ID {
+			t.Errorf("Employees[%d] = %s (%s), want %s (%s)", i, emp.Name, emp.ID, original[i].Name, original[i].ID)
+		}
+	}
+	if !reflect.DeepEqual(imported.Employees, original) {
+		t.Errorf("ApplyImport modified imported.Employees: %v, want %v", imported.Employees, original)
+	}
+
+	data.Employees[0].Hours = 40
+	if imported.Employees[0].Hours != 0 {
+		t.Errorf("data.Employees shares its backing array with imported.Employees")
+	}
+}
+
+func TestApplyImportZeroPeriodUsesCurrentPeriod(t *testing.T) {
+	imported := ImportedDepartment{
+		TotalHours: 10,
+		Employees:  []Employee{{Name: "علی", ID: "1"}},
+	}
+	data := DepartmentData{Period: Period{Year: 1403, Month: 1}}
+
+	before := CurrentPeriod()
+	ApplyImport(&data, imported)
+	after := CurrentPeriod()
+
+	if data.Period != before && data.Period != after {
+		t.Errorf("data.Period = %v, want current period %v", data.Period, after)
+	}
+	if data.Employees[0].Period != data.Period {
+		t.Errorf("Employees[0].Period = %v, want %v", data.Employees[0].Period, data.Period)
+	}
+	if !imported.Employees[0].Period.IsZero() {
+		t.Errorf("ApplyImport modified imported.Employees[0].Period: %v", imported.Employees[0].Period)
+	}
+}
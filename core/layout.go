@@ -0,0 +1,88 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// LayoutProfile قالب فایل اکسل یک واحد است: شیت حاوی اطلاعات، آدرس سلول (مانند "F1") یا نام محدوده
+// (Named Range) سرانه، روزهای تولید و ماه، و شماره ردیف هدر جدول پرسنل. فیلدهای خالی مقدار پیش‌فرض دارند.
+type LayoutProfile struct {
+	Name               string `json:"name,omitempty"`
+	SheetName          string `json:"sheet,omitempty"`                // خالی یعنی اولین شیت فایل
+	TotalHoursCell     string `json:"total_hours_cell,omitempty"`     // خالی یعنی SeranehCell
+	ProductionDaysCell string `json:"production_days_cell,omitempty"` // خالی یعنی ProductionDaysCell
+	MonthCell          string `json:"month_cell,omitempty"`           // خالی یعنی MonthCell
+	HeaderRow          int    `json:"header_row,omitempty"`           // شماره ردیف هدر (از 1)؛ صفر یعنی ردیف اول
+}
+
+// DefaultLayoutProfile قالب پیش‌فرض (اولین شیت، سلول‌های F1 تا F3 و هدر در ردیف اول) را برمی‌گرداند.
+func DefaultLayoutProfile() LayoutProfile {
+	return LayoutProfile{}.Resolved()
+}
+
+// IsZero مشخص می‌کند که قالبی تعیین نشده است (همان قالب پیش‌فرض).
+func (p LayoutProfile) IsZero() bool {
+	return p == LayoutProfile{}
+}
+
+// Resolved قالب را با جایگزینی فیلدهای خالی با مقادیر پیش‌فرض برمی‌گرداند.
+func (p LayoutProfile) Resolved() LayoutProfile {
+	if p.Name == "" && p.IsZero() {
+		p.Name = "پیش‌فرض"
+	} else if p.Name == "" {
+		p.Name = "سفارشی"
+	}
+	if p.TotalHoursCell == "" {
+		p.TotalHoursCell = SeranehCell
+	}
+	if p.ProductionDaysCell == "" {
+		p.ProductionDaysCell = ProductionDaysCell
+	}
+	if p.MonthCell == "" {
+		p.MonthCell = MonthCell
+	}
+	if p.HeaderRow <= 0 {
+		p.HeaderRow = 1
+	}
+	return p
+}
+
+// CloudLink لینک دانلود فایل اکسل یک واحد به همراه قالب آن فایل است. در cloud_links.json، لینک بدون قالب
+// به شکل رشته (قالب قدیمی) و لینک دارای قالب به شکل شیء {"url": ...، "layout": {...}} ذخیره می‌شود.
+type CloudLink struct {
+	URL    string        `json:"url"`
+	Layout LayoutProfile `json:"layout"`
+}
+
+type cloudLinkObject struct {
+	URL    string         `json:"url"`
+	Layout *LayoutProfile `json:"layout,omitempty"`
+}
+
+// UnmarshalJSON هر دو شکل رشته (فقط لینک) و شیء (لینک و قالب) را می‌پذیرد.
+func (l *CloudLink) UnmarshalJSON(data []byte) error {
+	var url string
+	if err := json.Unmarshal(data, &url); err == nil {
+		*l = CloudLink{URL: url}
+		return nil
+	}
+	var obj cloudLinkObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("لینک باید رشته یا شیء {url, layout} باشد: %w", err)
+	}
+	*l = CloudLink{URL: obj.URL}
+	if obj.Layout != nil {
+		l.Layout = *obj.Layout
+	}
+	return nil
+}
+
+// MarshalJSON لینک بدون قالب را به شکل رشته می‌نویسد تا فایل با نسخه‌های قبلی سازگار بماند.
+func (l CloudLink) MarshalJSON() ([]byte, error) {
+	if l.Layout.IsZero() {
+		return json.Marshal(l.URL)
+	}
+	layout := l.Layout
+	return json.Marshal(cloudLinkObject{URL: l.URL, Layout: &layout})
+}
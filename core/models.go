@@ -10,87 +10,173 @@ import (
 
 // User struct ... (بدون تغییر)
 type User struct {
-	Username   string
-	Password   string
-	Role       string
-	Department string
+	Username   string `json:"username"`
+	Password   string `json:"password_hash"` // هش رمز عبور (bcrypt)
+	Role       string `json:"role"`
+	Department string `json:"department"`
+	Disabled   bool   `json:"disabled,omitempty"`
+	// MustChangePassword در ورود بعدی کاربر را به تغییر رمز عبور ملزم می‌کند (پس از بازنشانی رمز توسط مدیر).
+	MustChangePassword bool `json:"must_change_password,omitempty"`
+	// SessionGeneration با هر خروج، تغییر یا بازنشانی رمز افزایش می‌یابد و توکن‌های "مرا به خاطر بسپار" قبلی را باطل می‌کند.
+	SessionGeneration int `json:"session_generation,omitempty"`
+	// DepartmentShiftAccess فهرست صریح واحد-شیفت‌های قابل دسترسی کاربر است؛ اگر خالی باشد، دسترسی از Department تعیین می‌شود.
+	DepartmentShiftAccess []string `json:"department_shift_access,omitempty"`
 }
 
 // Employee struct ... (بدون تغییر)
 type Employee struct {
-	Name      string
-	ID        string
-	Hours     int
-	Locked    bool
-	MonthType string
+	Name           string  `json:"name"`
+	ID             string  `json:"id"`
+	Hours          int     `json:"hours"`
+	Locked         bool    `json:"locked"`
+	Period         Period  `json:"period"`
+	Weight         float64 `json:"weight,omitempty"`          // وزن برای روش توزیع وزنی (مثلا گروه شغلی)
+	ProductionDays int     `json:"production_days,omitempty"` // روزهای کارکرد فرد برای روش توزیع بر اساس روز تولید
+	Seniority      int     `json:"seniority,omitempty"`       // سابقه (سال) برای تقسیم باقیمانده بر اساس سابقه
+	MinHours       int     `json:"min_hours,omitempty"`       // حداقل تضمین شده؛ صفر یعنی استفاده از پیش‌فرض واحد
+	MaxHours       int     `json:"max_hours,omitempty"`       // سقف ماهانه؛ صفر یعنی استفاده از پیش‌فرض واحد
 }
 
 // DepartmentData struct ... (بدون تغییر)
 type DepartmentData struct {
-	DepartmentShiftName string
-	TotalHours          int
-	ProductionDays      int
-	MonthName           string
-	Employees           []Employee
+	DepartmentShiftName string             `json:"department_shift_name"`
+	TotalHours          int                `json:"total_hours"`
+	ProductionDays      int                `json:"production_days"`
+	Period              Period             `json:"period"`
+	Employees           []Employee         `json:"employees"`
+	Strategy            AllocationStrategy `json:"strategy,omitempty"`          // خالی به معنای تقسیم مساوی است
+	FillCapHours        int                `json:"fill_cap_hours,omitempty"`    // سقف هر نفر در روش "پر کردن تا سقف"
+	DefaultMinHours     int                `json:"default_min_hours,omitempty"` // حداقل پیش‌فرض هر نفر در این واحد
+	DefaultMaxHours     int                `json:"default_max_hours,omitempty"` // سقف پیش‌فرض هر نفر در این واحد؛ صفر یعنی سقف عمومی برنامه
 }
 
-var AllDepartmentsData = make(map[string]*DepartmentData)
+// Clone یک کپی مستقل از داده‌های واحد (به همراه لیست پرسنل) برمی‌گرداند.
+func (d *DepartmentData) Clone() DepartmentData {
+	clone := *d
+	clone.Employees = make([]Employee, len(d.Employees))
+	copy(clone.Employees, d.Employees)
+	return clone
+}
+
+// AllocationStrategy روش توزیع سرانه بین پرسنل قفل نشده را مشخص می‌کند.
+type AllocationStrategy string
+
+const (
+	StrategyEqual          AllocationStrategy = "equal"
+	StrategyWeighted       AllocationStrategy = "weighted"
+	StrategyProductionDays AllocationStrategy = "production_days"
+	StrategySeniority      AllocationStrategy = "seniority"
+	StrategyFillToCap      AllocationStrategy = "fill_to_cap"
+)
+
+// AllocationStrategies ترتیب نمایش روش‌های توزیع در رابط کاربری است.
+var AllocationStrategies = []AllocationStrategy{
+	StrategyEqual, StrategyWeighted, StrategyProductionDays, StrategySeniority, StrategyFillToCap,
+}
 
-// CloudLinkInfo struct ... (بدون تغییر)
-type CloudLinkInfo struct {
-	DepartmentShiftName string `json:"department_shift_name"`
-	URL                 string `json:"url"`
+// DisplayName نام فارسی روش توزیع را برمی‌گرداند.
+func (s AllocationStrategy) DisplayName() string {
+	switch s {
+	case StrategyWeighted:
+		return "متناسب با وزن"
+	case StrategyProductionDays:
+		return "متناسب با روزهای کارکرد"
+	case StrategySeniority:
+		return "مساوی، باقیمانده بر اساس سابقه"
+	case StrategyFillToCap:
+		return "پر کردن تا سقف"
+	}
+	return "تقسیم مساوی"
+}
+
+// AllocationStrategyByDisplayName روش توزیع متناظر با نام فارسی را برمی‌گرداند.
+func AllocationStrategyByDisplayName(name string) (AllocationStrategy, bool) {
+	for _, s := range AllocationStrategies {
+		if s.DisplayName() == name {
+			return s, true
+		}
+	}
+	return "", false
 }
 
+var AllDepartmentsData = make(map[string]*DepartmentData)
+
 var PersianMonthNames = []string{"فروردین", "اردیبهشت", "خرداد", "تیر", "مرداد", "شهریور", "مهر", "آبان", "آذر", "دی", "بهمن", "اسفند"}
 
 const (
-	SeranehCell         = "F1"
-	ProductionDaysCell  = "F2"
-	MonthCell           = "F3"
-	EmployeeDataColDept = 0
-	EmployeeDataColName = 1
-	EmployeeDataColID   = 2
+	SeranehCell        = "F1"
+	ProductionDaysCell = "F2"
+	MonthCell          = "F3"
+
+	// ستون‌های شیت سرانه واحدها (برای ورود اطلاعات چند واحد از یک فایل)
+	BudgetColDeptShift      = 0
+	BudgetColTotalHours     = 1
+	BudgetColProductionDays = 2
+	BudgetColPeriod         = 3
 )
 
-var DepartmentShifts = map[string][]string{
-	"انبار":             {"شیفتی", "ثابت"},
-	"حراست":             {"نگهبانی", "باسکول"},
-	"برق":               {"شیفتی", "ثابت"},
-	"تولید":             {"شیفتی", "ثابت"},
-	"تأسیسات":           {"شیفتی", "ثابت"},
-	"مکانیک":            {"شیفتی", "ثابت"},
-	"سرمایه های انسانی": {"شیفتی", "ثابت"},
-	"کنترل کیفیت":       {"شیفتی", "ثابت"},
-	"تراشکاری":          {"شیفتی"},
-	"نت":                {"ثابت"},
-	"مهندسی سیستم":      {"ثابت"},
-	"فناوری اطلاعات":    {"ثابت"},
-	"برنامه ریزی":       {"ثابت"},
-	"مدیریت":            {"ثابت"},
-	"فروش":              {"ثابت"},
-	"دفتر فنی":          {"ثابت"},
-	"تدارکات":           {"ثابت"},
-	"مالی":              {"ثابت"},
-	"HSE":               {"شیفتی", "ثابت"},
-	"رؤسا و سرپرستان فنی مهندسی": {"ثابت"},
-	"مدیران و رؤسا":              {"ثابت"},
+// BudgetSheetNames نام‌های قابل قبول شیت سرانه واحدها در فایل ورود اطلاعات است.
+var BudgetSheetNames = []string{"سرانه", "سرانه واحدها", "بودجه", "Budget"}
+
+// DepartmentBudget سرانه، روزهای تولید و دوره یک واحد-شیفت در شیت سرانه واحدها است.
+// دوره صفر یعنی ستون ماه برای این واحد خالی بوده است.
+type DepartmentBudget struct {
+	TotalHours     int
+	ProductionDays int
+	Period         Period
 }
 
-var ManageableDepartments []string
-var defaultEmbeddedCloudLinks map[string]string // متغیر پکیج برای نگهداری لینک‌های پیش‌فرض
+// DepartmentShifts شیفت‌های هر واحد و DepartmentGroups گروه‌های ویژه‌ای هستند که کاربر می‌تواند به جای یک
+// واحد عضو آن‌ها باشد و به همه واحد-شیفت‌های فهرست شده دسترسی دارد. هر دو از ساختار سازمانی
+// (InitializeOrgStructure) پر می‌شوند.
+var (
+	DepartmentShifts = map[string][]string{}
+	DepartmentGroups = map[string][]string{}
+)
 
-func init() {
-	for dept, shifts := range DepartmentShifts {
-		for _, shift := range shifts {
-			ManageableDepartments = append(ManageableDepartments, dept+" - "+shift)
+// نقش‌های پیش‌فرض کاربران (توانمندی هر نقش در فایل نقش‌ها تعریف می‌شود) و مقدار ویژه دپارتمان برای دسترسی به همه واحدها
+const (
+	RoleAdmin          = "admin"
+	RoleDepartmentHead = "department_head"
+	AllDepartments     = "all"
+)
+
+// UserDepartmentOptions مقادیر مجاز دپارتمان کاربر (همه واحدها، گروه‌های ویژه و واحدهای تعریف شده) را برمی‌گرداند.
+func UserDepartmentOptions() []string {
+	options := []string{AllDepartments}
+	var names []string
+	for group := range DepartmentGroups {
+		names = append(names, group)
+	}
+	for dept := range DepartmentShifts {
+		if _, isGroup := DepartmentGroups[dept]; !isGroup {
+			names = append(names, dept)
 		}
 	}
-	sort.Strings(ManageableDepartments) // مرتب‌سازی برای نمایش یکسان
+	sort.Strings(names)
+	return append(options, names...)
+}
+
+// IsValidUserDepartment مشخص می‌کند که دپارتمان در core.DepartmentShifts یا گروه‌های ویژه تعریف شده است.
+func IsValidUserDepartment(dept string) bool {
+	if dept == AllDepartments {
+		return true
+	}
+	if _, ok := DepartmentGroups[dept]; ok {
+		return true
+	}
+	_, ok := DepartmentShifts[dept]
+	return ok
+}
+
+// ManageableDepartments همه واحد-شیفت‌های ساختار سازمانی (مانند "انبار - شیفتی") به ترتیب الفبا است.
+var ManageableDepartments []string
+var defaultEmbeddedCloudLinks map[string]CloudLink // متغیر پکیج برای نگهداری لینک‌های پیش‌فرض
 
+func init() {
 	// مقداردهی اولیه defaultEmbeddedCloudLinks در اینجا انجام نمی‌شود،
 	// بلکه از طریق InitializeDefaultCloudLinks که از main.go با داده‌های embed شده فراخوانی می‌شود.
-	defaultEmbeddedCloudLinks = make(map[string]string)
+	defaultEmbeddedCloudLinks = make(map[string]CloudLink)
 }
 
 // InitializeDefaultCloudLinks مقادیر پیش‌فرض لینک‌های ابری را از داده‌های embed شده بارگذاری می‌کند.
@@ -110,9 +196,9 @@ func InitializeDefaultCloudLinks(jsonData []byte) {
 }
 
 // GetDefaultCloudLinks یک کپی از لینک‌های پیش‌فرض embed شده را برمی‌گرداند.
-func GetDefaultCloudLinks() map[string]string {
+func GetDefaultCloudLinks() map[string]CloudLink {
 	// برگرداندن یک کپی برای جلوگیری از تغییرات ناخواسته در مپ اصلی
-	linksCopy := make(map[string]string)
+	linksCopy := make(map[string]CloudLink)
 	for k, v := range defaultEmbeddedCloudLinks {
 		linksCopy[k] = v
 	}
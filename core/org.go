@@ -0,0 +1,224 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"overtime_go/utils"
+)
+
+const orgStructureFilename = "org_structure.json"
+
+// OrgDepartment یک واحد سازمانی با شیفت‌های آن است. DisplayName (اختیاری) فقط برای نمایش است و کلید
+// داده‌های ذخیره شده، لینک‌ها و دسترسی کاربران همچنان Name می‌ماند.
+type OrgDepartment struct {
+	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name,omitempty"`
+	Shifts      []string `json:"shifts"`
+}
+
+// OrgGroup یک گروه ویژه (مانند "فنی مهندسی") شامل چند واحد-شیفت است.
+type OrgGroup struct {
+	Name             string   `json:"name"`
+	DepartmentShifts []string `json:"department_shifts"`
+}
+
+// OrgStructure ساختار سازمانی (واحدها، شیفت‌ها و گروه‌های ویژه) است که در org_structure.json کنار برنامه
+// قابل ویرایش است و در نبود آن، از فایل پیش‌فرض جاسازی شده خوانده می‌شود.
+type OrgStructure struct {
+	Departments []OrgDepartment `json:"departments"`
+	Groups      []OrgGroup      `json:"groups"`
+}
+
+var (
+	defaultOrgStructure OrgStructure
+	currentOrgStructure OrgStructure
+	departmentLabels    = make(map[string]string) // واحد-شیفت ← نام نمایشی
+)
+
+// DepartmentShiftKey کلید واحد-شیفت (مثلا "انبار - شیفتی") را می‌سازد.
+func DepartmentShiftKey(department, shift string) string {
+	return department + " - " + shift
+}
+
+func orgStructureFilePath() string {
+	appDir, err := utils.GetExecutableDir()
+	if err != nil {
+		fmt.Printf("هشدار: خطا در گرفتن مسیر فایل اجرایی برای %s: %v. تلاش برای مسیر فعلی.\n", orgStructureFilename, err)
+		appDir, _ = os.Getwd()
+	}
+	return filepath.Join(appDir, orgStructureFilename)
+}
+
+// InitializeOrgStructure ساختار سازمانی پیش‌فرض را از داده جاسازی شده می‌خواند و اگر فایل org_structure.json
+// کنار برنامه وجود داشته و معتبر باشد، آن را به جای پیش‌فرض اعمال می‌کند. این تابع از main.go و پیش از
+// بارگذاری نقش‌ها و کاربران فراخوانی می‌شود.
+func InitializeOrgStructure(defaultJSON []byte) error {
+	if err := json.Unmarshal(defaultJSON, &defaultOrgStructure); err != nil {
+		return fmt.Errorf("خطا در پارس کردن ساختار سازمانی پیش‌فرض: %w", err)
+	}
+	structure := defaultOrgStructure
+
+	filePath := orgStructureFilePath()
+	fileData, err := os.ReadFile(filePath)
+	if err == nil {
+		var fromFile OrgStructure
+		if errJSON := json.Unmarshal(fileData, &fromFile); errJSON != nil {
+			fmt.Printf("هشدار: خطا در پارس کردن '%s': %v. استفاده از ساختار سازمانی پیش‌فرض.\n", filePath, errJSON)
+		} else if errValidate := fromFile.Validate(); errValidate != nil {
+			fmt.Printf("هشدار: ساختار سازمانی '%s' نامعتبر است: %v. استفاده از ساختار سازمانی پیش‌فرض.\n", filePath, errValidate)
+		} else {
+			structure = fromFile
+			fmt.Printf("ساختار سازمانی از فایل '%s' بارگذاری شد.\n", filePath)
+		}
+	} else if !os.IsNotExist(err) {
+		fmt.Printf("هشدار: خطا در خواندن '%s': %v. استفاده از ساختار سازمانی پیش‌فرض.\n", filePath, err)
+	}
+	applyOrgStructure(structure)
+	return nil
+}
+
+// Validate نام‌های تکراری یا خالی، واحد بدون شیفت و اعضای ناموجود گروه‌ها را گزارش می‌کند.
+func (o OrgStructure) Validate() error {
+	if len(o.Departments) == 0 {
+		return fmt.Errorf("ساختار سازمانی هیچ واحدی ندارد")
+	}
+	keys := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, dept := range o.Departments {
+		name := strings.TrimSpace(dept.Name)
+		if name == "" {
+			return fmt.Errorf("نام واحد نمی‌تواند خالی باشد")
+		}
+		if strings.Contains(name, " - ") {
+			return fmt.Errorf("نام واحد '%s' نباید شامل ' - ' باشد", name)
+		}
+		if names[name] {
+			return fmt.Errorf("واحد '%s' بیش از یک بار تعریف شده است", name)
+		}
+		names[name] = true
+		if len(dept.Shifts) == 0 {
+			return fmt.Errorf("واحد '%s' هیچ شیفتی ندارد", name)
+		}
+		for _, shift := range dept.Shifts {
+			shift = strings.TrimSpace(shift)
+			if shift == "" {
+				return fmt.Errorf("نام شیفت واحد '%s' نمی‌تواند خالی باشد", name)
+			}
+			key := DepartmentShiftKey(name, shift)
+			if keys[key] {
+				return fmt.Errorf("شیفت '%s' در واحد '%s' تکراری است", shift, name)
+			}
+			keys[key] = true
+		}
+	}
+	groupNames := make(map[string]bool)
+	for _, group := range o.Groups {
+		name := strings.TrimSpace(group.Name)
+		if name == "" {
+			return fmt.Errorf("نام گروه نمی‌تواند خالی باشد")
+		}
+		if name == AllDepartments {
+			return fmt.Errorf("نام گروه '%s' رزرو شده است", AllDepartments)
+		}
+		if groupNames[name] {
+			return fmt.Errorf("گروه '%s' بیش از یک بار تعریف شده است", name)
+		}
+		groupNames[name] = true
+		for _, member := range group.DepartmentShifts {
+			if !keys[strings.TrimSpace(member)] {
+				return fmt.Errorf("واحد-شیفت '%s' در گروه '%s' در ساختار سازمانی تعریف نشده است", member, name)
+			}
+		}
+	}
+	return nil
+}
+
+// applyOrgStructure متغیرهای DepartmentShifts، DepartmentGroups و ManageableDepartments را از ساختار داده شده می‌سازد.
+func applyOrgStructure(o OrgStructure) {
+	shifts := make(map[string][]string, len(o.Departments))
+	groups := make(map[string][]string, len(o.Groups))
+	labels := make(map[string]string)
+	var manageable []string
+	for _, dept := range o.Departments {
+		name := strings.TrimSpace(dept.Name)
+		for _, shift := range dept.Shifts {
+			shift = strings.TrimSpace(shift)
+			shifts[name] = append(shifts[name], shift)
+			key := DepartmentShiftKey(name, shift)
+			manageable = append(manageable, key)
+			if display := strings.TrimSpace(dept.DisplayName); display != "" && display != name {
+				labels[key] = DepartmentShiftKey(display, shift)
+			}
+		}
+	}
+	for _, group := range o.Groups {
+		name := strings.TrimSpace(group.Name)
+		for _, member := range group.DepartmentShifts {
+			groups[name] = append(groups[name], strings.TrimSpace(member))
+		}
+	}
+	sort.Strings(manageable) // مرتب‌سازی برای نمایش یکسان
+
+	currentOrgStructure = o
+	DepartmentShifts = shifts
+	DepartmentGroups = groups
+	ManageableDepartments = manageable
+	departmentLabels = labels
+}
+
+// CurrentOrgStructure یک کپی از ساختار سازمانی فعلی را برای ویرایش برمی‌گرداند.
+func CurrentOrgStructure() OrgStructure {
+	return cloneOrgStructure(currentOrgStructure)
+}
+
+// DefaultOrgStructure یک کپی از ساختار سازمانی پیش‌فرض جاسازی شده را برمی‌گرداند.
+func DefaultOrgStructure() OrgStructure {
+	return cloneOrgStructure(defaultOrgStructure)
+}
+
+func cloneOrgStructure(o OrgStructure) OrgStructure {
+	clone := OrgStructure{
+		Departments: make([]OrgDepartment, len(o.Departments)),
+		Groups:      make([]OrgGroup, len(o.Groups)),
+	}
+	for i, dept := range o.Departments {
+		dept.Shifts = append([]string(nil), dept.Shifts...)
+		clone.Departments[i] = dept
+	}
+	for i, group := range o.Groups {
+		group.DepartmentShifts = append([]string(nil), group.DepartmentShifts...)
+		clone.Groups[i] = group
+	}
+	return clone
+}
+
+// SaveOrgStructure ساختار سازمانی را پس از اعتبارسنجی در org_structure.json کنار برنامه ذخیره و بلافاصله اعمال می‌کند.
+func SaveOrgStructure(o OrgStructure) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	fileData, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return fmt.Errorf("خطا در تبدیل ساختار سازمانی به JSON: %w", err)
+	}
+	filePath := orgStructureFilePath()
+	if err := os.WriteFile(filePath, fileData, 0644); err != nil {
+		return fmt.Errorf("خطا در نوشتن فایل %s در مسیر '%s': %w", orgStructureFilename, filePath, err)
+	}
+	applyOrgStructure(o)
+	fmt.Printf("فایل %s با موفقیت در مسیر '%s' ذخیره شد.\n", orgStructureFilename, filePath)
+	return nil
+}
+
+// DepartmentShiftDisplayName نام نمایشی یک واحد-شیفت را برمی‌گرداند؛ در نبود نام نمایشی، خود کلید.
+func DepartmentShiftDisplayName(deptShift string) string {
+	if label, ok := departmentLabels[deptShift]; ok {
+		return label
+	}
+	return deptShift
+}
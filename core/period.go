@@ -0,0 +1,180 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jalaali/go-jalaali"
+)
+
+// Period یک دوره تخصیص (سال و ماه شمسی) است. مقدار صفر به معنای دوره نامشخص است.
+type Period struct {
+	Year  int `json:"year"`
+	Month int `json:"month"` // 1 تا 12
+}
+
+const (
+	minPeriodYear = 1300
+	maxPeriodYear = 1500
+)
+
+// NewPeriod یک دوره معتبر می‌سازد.
+func NewPeriod(year, month int) (Period, error) {
+	if year < minPeriodYear || year > maxPeriodYear {
+		return Period{}, fmt.Errorf("سال شمسی %d نامعتبر است", year)
+	}
+	if month < 1 || month > len(PersianMonthNames) {
+		return Period{}, fmt.Errorf("ماه %d نامعتبر است", month)
+	}
+	return Period{Year: year, Month: month}, nil
+}
+
+// PeriodOf دوره شمسی متناظر با یک زمان میلادی را برمی‌گرداند.
+func PeriodOf(t time.Time) Period {
+	jy, jm, _, err := jalaali.ToJalaali(t.Year(), t.Month(), t.Day())
+	if err != nil {
+		fmt.Printf("هشدار: تبدیل تاریخ %s به شمسی ممکن نیست: %v\n", t.Format("2006-01-02"), err)
+		return Period{}
+	}
+	return Period{Year: jy, Month: int(jm)}
+}
+
+// CurrentPeriod دوره شمسی جاری سیستم را برمی‌گرداند.
+func CurrentPeriod() Period {
+	return PeriodOf(time.Now())
+}
+
+// IsZero مشخص می‌کند که دوره تعیین نشده است.
+func (p Period) IsZero() bool {
+	return p.Year == 0 && p.Month == 0
+}
+
+// MonthName نام فارسی ماه دوره را برمی‌گرداند.
+func (p Period) MonthName() string {
+	if p.Month < 1 || p.Month > len(PersianMonthNames) {
+		return ""
+	}
+	return PersianMonthNames[p.Month-1]
+}
+
+// String دوره را به شکل "فروردین 1404" برمی‌گرداند.
+func (p Period) String() string {
+	if p.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%s %d", p.MonthName(), p.Year)
+}
+
+// Key کلید قابل مرتب‌سازی دوره (مثلا "1404-01") برای نام پوشه‌ها و فایل‌ها است.
+func (p Period) Key() string {
+	return fmt.Sprintf("%04d-%02d", p.Year, p.Month)
+}
+
+// Before مشخص می‌کند که دوره p قبل از other است.
+func (p Period) Before(other Period) bool {
+	if p.Year != other.Year {
+		return p.Year < other.Year
+	}
+	return p.Month < other.Month
+}
+
+// AddMonths دوره‌ای که n ماه بعد (یا با n منفی، قبل) از p است را برمی‌گرداند.
+func (p Period) AddMonths(n int) Period {
+	index := p.Year*12 + (p.Month - 1) + n
+	return Period{Year: index / 12, Month: index%12 + 1}
+}
+
+var (
+	periodYearMonthPattern = regexp.MustCompile(`^(\d{4})\s*[/\-.]\s*(\d{1,2})$`)
+	periodMonthYearPattern = regexp.MustCompile(`^(\d{1,2})\s*[/\-.]\s*(\d{4})$`)
+)
+
+// ParsePeriod یک دوره را از متن می‌خواند. قالب‌های پشتیبانی شده:
+// "۱۴۰۴/۰۱"، "1404-1"، "01/1404"، "فروردین ۱۴۰۴"، "۱۴۰۴ فروردین" و نام ماه به تنهایی
+// (که در این حالت آخرین همان ماه تا دوره جاری در نظر گرفته می‌شود؛ مثلا "اسفند" در فروردین 1404 یعنی اسفند 1403).
+func ParsePeriod(s string) (Period, error) {
+	return parsePeriod(s, CurrentPeriod())
+}
+
+// parsePeriod همان ParsePeriod است که سال ماه بدون سال را نسبت به دوره current تعیین می‌کند.
+func parsePeriod(s string, current Period) (Period, error) {
+	text := strings.TrimSpace(NormalizePersianText(s))
+	if text == "" {
+		return Period{}, fmt.Errorf("مقدار دوره خالی است")
+	}
+
+	if m := periodYearMonthPattern.FindStringSubmatch(text); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		return NewPeriod(year, month)
+	}
+	if m := periodMonthYearPattern.FindStringSubmatch(text); m != nil {
+		month, _ := strconv.Atoi(m[1])
+		year, _ := strconv.Atoi(m[2])
+		return NewPeriod(year, month)
+	}
+
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ' ' || r == '/' || r == '-' || r == '،' || r == ','
+	})
+	month, year := 0, 0
+	for _, field := range fields {
+		if idx := MonthIndex(field); idx > 0 {
+			month = idx
+			continue
+		}
+		if val, err := strconv.Atoi(field); err == nil && len(field) == 4 {
+			year = val
+			continue
+		}
+		return Period{}, fmt.Errorf("بخش '%s' در دوره '%s' قابل تشخیص نیست", field, s)
+	}
+	if month == 0 {
+		return Period{}, fmt.Errorf("نام ماه در '%s' یافت نشد", s)
+	}
+	if year == 0 {
+		year = current.Year
+		if month > current.Month {
+			year--
+		}
+	}
+	return NewPeriod(year, month)
+}
+
+// MonthIndex شماره ماه (1 تا 12) متناظر با نام فارسی ماه را برمی‌گرداند؛ صفر یعنی نام نامعتبر.
+func MonthIndex(name string) int {
+	normalized := strings.TrimSpace(NormalizePersianText(name))
+	for i, m := range PersianMonthNames {
+		if m == normalized {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// NormalizePersianText ارقام فارسی و عربی را به لاتین و حروف عربی (ي، ك) را به معادل فارسی تبدیل
+// و نویسه‌های نامرئی جهت متن را حذف می‌کند.
+func NormalizePersianText(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r >= '۰' && r <= '۹':
+			b.WriteRune('0' + (r - '۰'))
+		case r >= '٠' && r <= '٩':
+			b.WriteRune('0' + (r - '٠'))
+		case r == 'ي' || r == 'ى':
+			b.WriteRune('ی')
+		case r == 'ك':
+			b.WriteRune('ک')
+		case r == '\u200e' || r == '\u200f' || r == '\u061c' || (r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069'):
+			// نویسه‌های کنترل جهت متن (LRM، RLM، ALM و ...) حذف می‌شوند
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package core
+
+import "testing"
+
+func TestParsePeriod(t *testing.T) {
+	current := Period{Year: 1404, Month: 1}
+	tests := []struct {
+		name    string
+		input   string
+		want    Period
+		wantErr bool
+	}{
+		{name: "persian digits year/month", input: "۱۴۰۴/۰۱", want: Period{Year: 1404, Month: 1}},
+		{name: "latin year-month", input: "1404-1", want: Period{Year: 1404, Month: 1}},
+		{name: "month/year", input: "12/1403", want: Period{Year: 1403, Month: 12}},
+		{name: "month name then year", input: "فروردین ۱۴۰۴", want: Period{Year: 1404, Month: 1}},
+		{name: "year then month name", input: "۱۴۰۳ اسفند", want: Period{Year: 1403, Month: 12}},
+		{name: "arabic-indic digits", input: "١٤٠٤/٠٢", want: Period{Year: 1404, Month: 2}},
+		{name: "arabic yeh in month name", input: "دي 1403", want: Period{Year: 1403, Month: 10}},
+		{name: "arabic yeh in ordibehesht", input: "ارديبهشت ۱۴۰۴", want: Period{Year: 1404, Month: 2}},
+		{name: "bidi marks", input: "\u200fفروردین\u200e \u202b۱۴۰۴\u202c", want: Period{Year: 1404, Month: 1}},
+		{name: "surrounding spaces", input: "  1404 / 05  ", want: Period{Year: 1404, Month: 5}},
+		{name: "missing year for current month", input: "فروردین", want: Period{Year: 1404, Month: 1}},
+		{name: "missing year for later month uses previous year", input: "اسفند", want: Period{Year: 1403, Month: 12}},
+		{name: "year too small", input: "1299/01", wantErr: true},
+		{name: "year too large", input: "فروردین 1501", wantErr: true},
+		{name: "month out of range", input: "1404/13", wantErr: true},
+		{name: "month zero", input: "1404/0", wantErr: true},
+		{name: "empty", input: "  ", wantErr: true},
+		{name: "no month name", input: "1404", wantErr: true},
+		{name: "unknown word", input: "فروردین سال 1404", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePeriod(tt.input, current)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parsePeriod(%q) = %v, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePeriod(%q) error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("parsePeriod(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePeriodMissingYearMidYear(t *testing.T) {
+	current := Period{Year: 1404, Month: 7}
+	tests := map[string]Period{
+		"شهریور": {Year: 1404, Month: 6},
+		"مهر":    {Year: 1404, Month: 7},
+		"آبان":   {Year: 1403, Month: 8},
+	}
+	for input, want := range tests {
+		got, err := parsePeriod(input, current)
+		if err != nil {
+			t.Fatalf("parsePeriod(%q) error: %v", input, err)
+		}
+		if got != want {
+			t.Errorf("parsePeriod(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestNormalizePersianTextArabicKaf(t *testing.T) {
+	if got, want := NormalizePersianText("كد ي"), "کد ی"; got != want {
+		t.Errorf("NormalizePersianText = %q, want %q", got, want)
+	}
+}
+
+func TestPeriodAddMonths(t *testing.T) {
+	tests := []struct {
+		start Period
+		n     int
+		want  Period
+	}{
+		{Period{Year: 1404, Month: 1}, 0, Period{Year: 1404, Month: 1}},
+		{Period{Year: 1404, Month: 1}, 1, Period{Year: 1404, Month: 2}},
+		{Period{Year: 1404, Month: 12}, 1, Period{Year: 1405, Month: 1}},
+		{Period{Year: 1404, Month: 1}, -1, Period{Year: 1403, Month: 12}},
+		{Period{Year: 1404, Month: 6}, -18, Period{Year: 1402, Month: 12}},
+		{Period{Year: 1404, Month: 6}, 30, Period{Year: 1406, Month: 12}},
+	}
+	for _, tt := range tests {
+		if got := tt.start.AddMonths(tt.n); got != tt.want {
+			t.Errorf("%v.AddMonths(%d) = %v, want %v", tt.start, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestPeriodBefore(t *testing.T) {
+	tests := []struct {
+		p, other Period
+		want     bool
+	}{
+		{Period{Year: 1403, Month: 12}, Period{Year: 1404, Month: 1}, true},
+		{Period{Year: 1404, Month: 1}, Period{Year: 1403, Month: 12}, false},
+		{Period{Year: 1404, Month: 2}, Period{Year: 1404, Month: 3}, true},
+		{Period{Year: 1404, Month: 3}, Period{Year: 1404, Month: 3}, false},
+		{Period{}, Period{Year: 1404, Month: 1}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Before(tt.other); got != tt.want {
+			t.Errorf("%v.Before(%v) = %v, want %v", tt.p, tt.other, got, tt.want)
+		}
+	}
+}
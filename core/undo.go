@@ -0,0 +1,60 @@
+package core
+
+// DefaultUndoLimit حداکثر تعداد مراحل قابل بازگشت برای هر واحد است.
+const DefaultUndoLimit = 50
+
+// UndoStack پشته بازگشت/انجام مجدد نسخه‌های داده یک واحد است. هر نسخه یک کپی مستقل (Clone) است.
+type UndoStack struct {
+	undo  []DepartmentData
+	redo  []DepartmentData
+	limit int
+}
+
+// NewUndoStack یک پشته با حداکثر limit مرحله می‌سازد؛ limit صفر یا منفی یعنی DefaultUndoLimit.
+func NewUndoStack(limit int) *UndoStack {
+	if limit <= 0 {
+		limit = DefaultUndoLimit
+	}
+	return &UndoStack{limit: limit}
+}
+
+// Push وضعیت قبل از یک تغییر را ثبت می‌کند. هر تغییر جدید، مراحل انجام مجدد را پاک می‌کند.
+func (s *UndoStack) Push(before DepartmentData) {
+	s.undo = append(s.undo, before.Clone())
+	if len(s.undo) > s.limit {
+		s.undo = s.undo[len(s.undo)-s.limit:]
+	}
+	s.redo = nil
+}
+
+// CanUndo مشخص می‌کند که مرحله‌ای برای بازگشت وجود دارد.
+func (s *UndoStack) CanUndo() bool {
+	return len(s.undo) > 0
+}
+
+// CanRedo مشخص می‌کند که مرحله‌ای برای انجام مجدد وجود دارد.
+func (s *UndoStack) CanRedo() bool {
+	return len(s.redo) > 0
+}
+
+// Undo وضعیت قبلی را برمی‌گرداند و وضعیت فعلی (current) را برای انجام مجدد نگه می‌دارد.
+func (s *UndoStack) Undo(current DepartmentData) (DepartmentData, bool) {
+	if len(s.undo) == 0 {
+		return DepartmentData{}, false
+	}
+	previous := s.undo[len(s.undo)-1]
+	s.undo = s.undo[:len(s.undo)-1]
+	s.redo = append(s.redo, current.Clone())
+	return previous, true
+}
+
+// Redo آخرین تغییر برگردانده شده را دوباره برمی‌گرداند و وضعیت فعلی را برای بازگشت نگه می‌دارد.
+func (s *UndoStack) Redo(current DepartmentData) (DepartmentData, bool) {
+	if len(s.redo) == 0 {
+		return DepartmentData{}, false
+	}
+	next := s.redo[len(s.redo)-1]
+	s.redo = s.redo[:len(s.redo)-1]
+	s.undo = append(s.undo, current.Clone())
+	return next, true
+}
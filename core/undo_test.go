@@ -0,0 +1,132 @@
+package core
+
+import "testing"
+
+func undoState(total int) DepartmentData {
+	return DepartmentData{
+		DepartmentShiftName: "انبار - شیفتی",
+		TotalHours:          total,
+		Employees:           []Employee{{Name: "علی", ID: "1", Hours: total}},
+	}
+}
+
+func TestUndoStackEmpty(t *testing.T) {
+	s := NewUndoStack(0)
+	if s.CanUndo() || s.CanRedo() {
+		t.Fatalf("new stack CanUndo, CanRedo = %v, %v; want false, false", s.CanUndo(), s.CanRedo())
+	}
+	if _, ok := s.Undo(undoState(1)); ok {
+		t.Error("Undo on empty stack returned ok")
+	}
+	if _, ok := s.Redo(undoState(1)); ok {
+		t.Error("Redo on empty stack returned ok")
+	}
+	if s.CanRedo() {
+		t.Error("failed Undo must not add a redo step")
+	}
+}
+
+func TestUndoStackUndoRedo(t *testing.T) {
+	s := NewUndoStack(0)
+	s.Push(undoState(1)) // 1 → 2
+	s.Push(undoState(2)) // 2 → 3
+	current := undoState(3)
+
+	got, ok := s.Undo(current)
+	if !ok || got.TotalHours != 2 {
+		t.Fatalf("Undo = %d, %v; want 2, true", got.TotalHours, ok)
+	}
+	current = got
+	got, ok = s.Undo(current)
+	if !ok || got.TotalHours != 1 {
+		t.Fatalf("second Undo = %d, %v; want 1, true", got.TotalHours, ok)
+	}
+	current = got
+	if s.CanUndo() {
+		t.Error("CanUndo after undoing every step = true")
+	}
+
+	got, ok = s.Redo(current)
+	if !ok || got.TotalHours != 2 {
+		t.Fatalf("Redo = %d, %v; want 2, true", got.TotalHours, ok)
+	}
+	current = got
+	got, ok = s.Redo(current)
+	if !ok || got.TotalHours != 3 {
+		t.Fatalf("second Redo = %d, %v; want 3, true", got.TotalHours, ok)
+	}
+	if s.CanRedo() {
+		t.Error("CanRedo after redoing every step = true")
+	}
+	if !s.CanUndo() {
+		t.Error("CanUndo after redo = false")
+	}
+}
+
+func TestUndoStackPushClearsRedo(t *testing.T) {
+	s := NewUndoStack(0)
+	s.Push(undoState(1))
+	s.Push(undoState(2))
+	previous, _ := s.Undo(undoState(3))
+	if !s.CanRedo() {
+		t.Fatal("CanRedo after Undo = false")
+	}
+
+	s.Push(previous) // تغییر جدید پس از بازگشت
+	if s.CanRedo() {
+		t.Error("CanRedo after a new Push = true; redo steps must be cleared")
+	}
+	if _, ok := s.Redo(undoState(4)); ok {
+		t.Error("Redo after a new Push returned ok")
+	}
+}
+
+func TestUndoStackLimit(t *testing.T) {
+	s := NewUndoStack(0)
+	for i := 1; i <= DefaultUndoLimit+10; i++ {
+		s.Push(undoState(i))
+	}
+	current := undoState(DefaultUndoLimit + 11)
+	steps := 0
+	for s.CanUndo() {
+		var ok bool
+		current, ok = s.Undo(current)
+		if !ok {
+			t.Fatal("Undo returned false while CanUndo was true")
+		}
+		steps++
+	}
+	if steps != DefaultUndoLimit {
+		t.Errorf("undo steps = %d, want %d", steps, DefaultUndoLimit)
+	}
+	// قدیمی‌ترین مراحل حذف می‌شوند
+	if want := 11; current.TotalHours != want {
+		t.Errorf("oldest kept state TotalHours = %d, want %d", current.TotalHours, want)
+	}
+}
+
+func TestUndoStackCustomLimit(t *testing.T) {
+	s := NewUndoStack(3)
+	for i := 1; i <= 5; i++ {
+		s.Push(undoState(i))
+	}
+	current := undoState(6)
+	for s.CanUndo() {
+		current, _ = s.Undo(current)
+	}
+	if current.TotalHours != 3 {
+		t.Errorf("oldest kept state TotalHours = %d, want 3", current.TotalHours)
+	}
+}
+
+func TestUndoStackStoresCopies(t *testing.T) {
+	s := NewUndoStack(0)
+	before := undoState(1)
+	s.Push(before)
+	before.Employees[0].Hours = 99 // تغییر داده پس از ثبت نباید نسخه ذخیره شده را تغییر دهد
+
+	got, _ := s.Undo(undoState(2))
+	if got.Employees[0].Hours != 1 {
+		t.Errorf("stored state was mutated: Hours = %d, want 1", got.Employees[0].Hours)
+	}
+}
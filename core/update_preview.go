@@ -0,0 +1,114 @@
+package core
+
+// EmployeeRename پرسنلی است که کد پرسنلی او در فایل جدید باقی مانده اما نامش تغییر کرده است.
+type EmployeeRename struct {
+	ID      string
+	OldName string
+	NewName string
+}
+
+// EmployeeIDChange پرسنلی است که با همان نام اما با کد پرسنلی متفاوت در فایل جدید آمده است.
+type EmployeeIDChange struct {
+	Name  string
+	OldID string
+	NewID string
+}
+
+// UpdatePreview خلاصه تفاوت داده فعلی یک واحد با داده دریافت شده از سرور است.
+type UpdatePreview struct {
+	Added             []Employee
+	Removed           []Employee
+	Renamed           []EmployeeRename
+	IDChanged         []EmployeeIDChange
+	OldTotalHours     int
+	NewTotalHours     int
+	OldProductionDays int
+	NewProductionDays int
+	OldPeriod         Period
+	NewPeriod         Period
+	KeepableLocks     int // تعداد پرسنل قفل شده‌ای که در فایل جدید هم وجود دارند
+}
+
+// HasChanges مشخص می‌کند که داده دریافتی با داده فعلی تفاوتی دارد یا نه.
+func (p UpdatePreview) HasChanges() bool {
+	return len(p.Added) > 0 || len(p.Removed) > 0 || len(p.Renamed) > 0 || len(p.IDChanged) > 0 ||
+		p.OldTotalHours != p.NewTotalHours || p.OldProductionDays != p.NewProductionDays || p.OldPeriod != p.NewPeriod
+}
+
+// PreviewUpdate داده فعلی واحد را با داده دریافتی مقایسه می‌کند. پرسنل با کد پرسنلی تطبیق داده می‌شوند؛
+// پرسنل حذف و اضافه شده‌ای که نام یکسان دارند به عنوان تغییر کد پرسنلی گزارش می‌شوند.
+func PreviewUpdate(current, incoming DepartmentData) UpdatePreview {
+	preview := UpdatePreview{
+		OldTotalHours:     current.TotalHours,
+		NewTotalHours:     incoming.TotalHours,
+		OldProductionDays: current.ProductionDays,
+		NewProductionDays: incoming.ProductionDays,
+		OldPeriod:         current.Period,
+		NewPeriod:         incoming.Period,
+	}
+
+	currentByID := make(map[string]Employee, len(current.Employees))
+	for _, emp := range current.Employees {
+		currentByID[emp.ID] = emp
+	}
+	incomingIDs := make(map[string]bool, len(incoming.Employees))
+	var added []Employee
+	for _, emp := range incoming.Employees {
+		incomingIDs[emp.ID] = true
+		old, ok := currentByID[emp.ID]
+		if !ok {
+			added = append(added, emp)
+			continue
+		}
+		if old.Name != emp.Name {
+			preview.Renamed = append(preview.Renamed, EmployeeRename{ID: emp.ID, OldName: old.Name, NewName: emp.Name})
+		}
+		if old.Locked && emp.ID != "" {
+			preview.KeepableLocks++
+		}
+	}
+
+	removedByName := make(map[string]int)
+	var removed []Employee
+	for _, emp := range current.Employees {
+		if !incomingIDs[emp.ID] {
+			removedByName[emp.Name] = len(removed)
+			removed = append(removed, emp)
+		}
+	}
+	matchedRemoved := make(map[int]bool)
+	for _, emp := range added {
+		if idx, ok := removedByName[emp.Name]; ok && !matchedRemoved[idx] {
+			matchedRemoved[idx] = true
+			preview.IDChanged = append(preview.IDChanged, EmployeeIDChange{Name: emp.Name, OldID: removed[idx].ID, NewID: emp.ID})
+			continue
+		}
+		preview.Added = append(preview.Added, emp)
+	}
+	for idx, emp := range removed {
+		if !matchedRemoved[idx] {
+			preview.Removed = append(preview.Removed, emp)
+		}
+	}
+	return preview
+}
+
+// KeepLockedHours ساعت و قفل پرسنل قفل شده فعلی را به پرسنل هم‌کد در لیست جدید منتقل می‌کند و
+// لیست حاصل را برمی‌گرداند. لیست incoming تغییر نمی‌کند.
+func KeepLockedHours(current, incoming []Employee) []Employee {
+	locked := make(map[string]Employee)
+	for _, emp := range current {
+		if emp.Locked && emp.ID != "" {
+			locked[emp.ID] = emp
+		}
+	}
+	result := make([]Employee, len(incoming))
+	copy(result, incoming)
+	for i := range result {
+		if old, ok := locked[result[i].ID]; ok {
+			result[i].Hours = old.Hours
+			result[i].Locked = true
+		}
+	}
+	return result
+}
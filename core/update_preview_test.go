@@ -0,0 +1,160 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPreviewUpdate(t *testing.T) {
+	tests := []struct {
+		name          string
+		current       []Employee
+		incoming      []Employee
+		wantAdded     []string // کد پرسنلی
+		wantRemoved   []string
+		wantRenamed   []EmployeeRename
+		wantIDChanged []EmployeeIDChange
+		wantKeepable  int
+	}{
+		{
+			name:     "no changes",
+			current:  []Employee{{Name: "علی", ID: "1"}, {Name: "رضا", ID: "2"}},
+			incoming: []Employee{{Name: "علی", ID: "1"}, {Name: "رضا", ID: "2"}},
+		},
+		{
+			name:        "added and removed",
+			current:     []Employee{{Name: "علی", ID: "1"}, {Name: "رضا", ID: "2"}},
+			incoming:    []Employee{{Name: "علی", ID: "1"}, {Name: "مریم", ID: "3"}},
+			wantAdded:   []string{"3"},
+			wantRemoved: []string{"2"},
+		},
+		{
+			name:        "renamed keeps id",
+			current:     []Employee{{Name: "علی", ID: "1"}},
+			incoming:    []Employee{{Name: "علی رضایی", ID: "1"}},
+			wantRenamed: []EmployeeRename{{ID: "1", OldName: "علی", NewName: "علی رضایی"}},
+		},
+		{
+			name:          "id changed keeps name",
+			current:       []Employee{{Name: "علی", ID: "1"}},
+			incoming:      []Employee{{Name: "علی", ID: "0001"}},
+			wantIDChanged: []EmployeeIDChange{{Name: "علی", OldID: "1", NewID: "0001"}},
+		},
+		{
+			name:        "rename plus id change of the same employee is a removal and an addition",
+			current:     []Employee{{Name: "علی", ID: "1"}},
+			incoming:    []Employee{{Name: "علی رضایی", ID: "0001"}},
+			wantAdded:   []string{"0001"},
+			wantRemoved: []string{"1"},
+		},
+		{
+			name:          "duplicate names match one removal each",
+			current:       []Employee{{Name: "علی", ID: "1"}, {Name: "علی", ID: "2"}},
+			incoming:      []Employee{{Name: "علی", ID: "3"}},
+			wantRemoved:   []string{"1"},
+			wantIDChanged: []EmployeeIDChange{{Name: "علی", OldID: "2", NewID: "3"}},
+		},
+		{
+			name: "locks kept only for employees still present",
+			current: []Employee{
+				{Name: "علی", ID: "1", Locked: true, Hours: 10},
+				{Name: "رضا", ID: "2", Locked: true, Hours: 20},
+				{Name: "مریم", ID: "3", Hours: 30},
+			},
+			incoming:     []Employee{{Name: "علی", ID: "1"}, {Name: "مریم", ID: "3"}},
+			wantRemoved:  []string{"2"},
+			wantKeepable: 1,
+		},
+		{
+			name:          "lock on employee whose id changed is not keepable",
+			current:       []Employee{{Name: "علی", ID: "1", Locked: true, Hours: 10}},
+			incoming:      []Employee{{Name: "علی", ID: "9"}},
+			wantIDChanged: []EmployeeIDChange{{Name: "علی", OldID: "1", NewID: "9"}},
+		},
+	}
+	ids := func(emps []Employee) []string {
+		var result []string
+		for _, emp := range emps {
+			result = append(result, emp.ID)
+		}
+		return result
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := DepartmentData{TotalHours: 100, ProductionDays: 20, Period: Period{Year: 1404, Month: 1}, Employees: tt.current}
+			incoming := DepartmentData{TotalHours: 100, ProductionDays: 20, Period: Period{Year: 1404, Month: 1}, Employees: tt.incoming}
+			preview := PreviewUpdate(current, incoming)
+
+			if got := ids(preview.Added); !reflect.DeepEqual(got, tt.wantAdded) {
+				t.Errorf("Added = %v, want %v", got, tt.wantAdded)
+			}
+			if got := ids(preview.Removed); !reflect.DeepEqual(got, tt.wantRemoved) {
+				t.Errorf("Removed = %v, want %v", got, tt.wantRemoved)
+			}
+			if !reflect.DeepEqual(preview.Renamed, tt.wantRenamed) {
+				t.Errorf("Renamed = %v, want %v", preview.Renamed, tt.wantRenamed)
+			}
+			if !reflect.DeepEqual(preview.IDChanged, tt.wantIDChanged) {
+				t.Errorf("IDChanged = %v, want %v", preview.IDChanged, tt.wantIDChanged)
+			}
+			if preview.KeepableLocks != tt.wantKeepable {
+				t.Errorf("KeepableLocks = %d, want %d", preview.KeepableLocks, tt.wantKeepable)
+			}
+			wantChanges := len(tt.wantAdded)+len(tt.wantRemoved)+len(tt.wantRenamed)+len(tt.wantIDChanged) > 0
+			if preview.HasChanges() != wantChanges {
+				t.Errorf("HasChanges() = %v, want %v", preview.HasChanges(), wantChanges)
+			}
+		})
+	}
+}
+
+func TestPreviewUpdateHeaderChanges(t *testing.T) {
+	emps := []Employee{{Name: "علی", ID: "1"}}
+	current := DepartmentData{TotalHours: 100, ProductionDays: 20, Period: Period{Year: 1404, Month: 1}, Employees: emps}
+	tests := map[string]DepartmentData{
+		"total hours":     {TotalHours: 120, ProductionDays: 20, Period: Period{Year: 1404, Month: 1}, Employees: emps},
+		"production days": {TotalHours: 100, ProductionDays: 22, Period: Period{Year: 1404, Month: 1}, Employees: emps},
+		"period":          {TotalHours: 100, ProductionDays: 20, Period: Period{Year: 1404, Month: 2}, Employees: emps},
+	}
+	for name, incoming := range tests {
+		preview := PreviewUpdate(current, incoming)
+		if !preview.HasChanges() {
+			t.Errorf("%s: HasChanges() = false, want true", name)
+		}
+		if preview.OldTotalHours != 100 || preview.NewTotalHours != incoming.TotalHours ||
+			preview.OldPeriod != current.Period || preview.NewPeriod != incoming.Period {
+			t.Errorf("%s: preview header = %+v", name, preview)
+		}
+	}
+}
+
+func TestKeepLockedHours(t *testing.T) {
+	current := []Employee{
+		{Name: "علی", ID: "1", Locked: true, Hours: 40},
+		{Name: "رضا", ID: "2", Locked: true, Hours: 25}, // در لیست جدید نیست
+		{Name: "مریم", ID: "3", Hours: 30},              // قفل نیست
+		{Name: "بی‌کد", ID: "", Locked: true, Hours: 5},
+	}
+	incoming := []Employee{
+		{Name: "علی رضایی", ID: "1", Hours: 0},
+		{Name: "مریم", ID: "3", Hours: 0},
+		{Name: "سارا", ID: "4", Hours: 0},
+		{Name: "بدون کد", ID: "", Hours: 0},
+	}
+	original := append([]Employee(nil), incoming...)
+
+	got := KeepLockedHours(current, incoming)
+
+	want := []Employee{
+		{Name: "علی رضایی", ID: "1", Hours: 40, Locked: true},
+		{Name: "مریم", ID: "3", Hours: 0},
+		{Name: "سارا", ID: "4", Hours: 0},
+		{Name: "بدون کد", ID: "", Hours: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("KeepLockedHours = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(incoming, original) {
+		t.Errorf("incoming was mutated: %+v", incoming)
+	}
+}
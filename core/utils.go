@@ -7,12 +7,11 @@ import (
 	"github.com/jalaali/go-jalaali"
 )
 
-func GetCurrentPersianMonthName() string {
-	now := time.Now()
-	_, jm, _, _ := jalaali.ToJalaali(now.Year(), now.Month(), now.Day())
-	if int(jm) >= 1 && int(jm) <= len(PersianMonthNames) {
-		return PersianMonthNames[int(jm)-1]
+// FormatJalaliDateTime زمان داده شده را به صورت تاریخ و ساعت شمسی (مثلا 1404/01/15 14:30) برمی‌گرداند.
+func FormatJalaliDateTime(t time.Time) string {
+	jy, jm, jd, err := jalaali.ToJalaali(t.Year(), t.Month(), t.Day())
+	if err != nil {
+		return t.Format("2006-01-02 15:04")
 	}
-	fmt.Println("هشدار: ماه شمسی جاری قابل تشخیص نیست، از اولین ماه استفاده می‌شود.")
-	return PersianMonthNames[0]
+	return fmt.Sprintf("%04d/%02d/%02d %s", jy, int(jm), jd, t.Format("15:04"))
 }
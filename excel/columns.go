@@ -0,0 +1,79 @@
+package excel
+
+import (
+	"fmt"
+	"strings"
+
+	"overtime_go/core"
+)
+
+// columnIndexes اندیس ستون‌های پیدا شده در ردیف هدر (کلید ستون ← اندیس، A=0) است.
+type columnIndexes map[string]int
+
+// cell مقدار ستون key در ردیف را برمی‌گرداند؛ ستون ناموجود رشته خالی است.
+func (c columnIndexes) cell(row []string, key string) string {
+	idx, ok := c[key]
+	if !ok || idx >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[idx])
+}
+
+func matchesColumn(def core.ColumnDefinition, normalizedHeader string) bool {
+	if core.NormalizeHeader(def.Title) == normalizedHeader {
+		return true
+	}
+	for _, synonym := range def.Synonyms {
+		if core.NormalizeHeader(synonym) == normalizedHeader {
+			return true
+		}
+	}
+	return false
+}
+
+// detectColumns ستون‌های فایل پرسنل را از روی متن ردیف هدر (با مترادف‌های core.EmployeeColumns) پیدا می‌کند.
+// اگر فقط برخی ستون‌های الزامی پیدا شوند، خطایی با نام ستون‌های گمشده برمی‌گرداند. فایل‌های قدیمی که هیچ
+// هدر شناخته شده‌ای ندارند با ستون‌های پیش‌فرض (DefaultIndex) خوانده می‌شوند و هشدار آن در report ثبت می‌شود.
+func detectColumns(header []string, sheetName string, headerRow int, report *Report) (columnIndexes, error) {
+	definitions := core.EmployeeColumns()
+	indexes := make(columnIndexes)
+	for colIdx, text := range header {
+		normalized := core.NormalizeHeader(text)
+		if normalized == "" {
+			continue
+		}
+		for _, def := range definitions {
+			if _, found := indexes[def.Key]; found {
+				continue
+			}
+			if matchesColumn(def, normalized) {
+				indexes[def.Key] = colIdx
+				break
+			}
+		}
+	}
+
+	requiredFound := 0
+	var missing []string
+	for _, def := range definitions {
+		if !def.Required {
+			continue
+		}
+		if _, found := indexes[def.Key]; found {
+			requiredFound++
+		} else {
+			missing = append(missing, fmt.Sprintf("- %s (عناوین قابل قبول: %s)", def.Title, strings.Join(def.Synonyms, "، ")))
+		}
+	}
+	if requiredFound == 0 {
+		report.addWarning(sheetName, "", headerRow, "هیچ هدر شناخته شده‌ای یافت نشد؛ ستون‌های پیش‌فرض (A نام واحد، B نام پرسنل، C کد پرسنلی و ...) استفاده می‌شوند.")
+		for _, def := range definitions {
+			indexes[def.Key] = def.DefaultIndex
+		}
+		return indexes, nil
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("ستون‌های الزامی زیر در ردیف هدر (ردیف %d) شیت '%s' یافت نشد:\n%s\nعناوین اضافی را می‌توان در فایل column_headers.json کنار برنامه تعریف کرد.", headerRow, sheetName, strings.Join(missing, "\n"))
+	}
+	return indexes, nil
+}
@@ -0,0 +1,142 @@
+package excel
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"overtime_go/core"
+	"overtime_go/resources"
+	"overtime_go/utils"
+)
+
+// initColumnHeaders عناوین پیش‌فرض ستون‌ها را به همراه column_headers.json کنار فایل اجرایی آزمون بارگذاری می‌کند.
+func initColumnHeaders(t *testing.T, overrideJSON string) {
+	t.Helper()
+	appDir, err := utils.GetExecutableDir()
+	if err != nil {
+		t.Fatalf("GetExecutableDir: %v", err)
+	}
+	overridePath := filepath.Join(appDir, "column_headers.json")
+	if overrideJSON != "" {
+		if err := os.WriteFile(overridePath, []byte(overrideJSON), 0600); err != nil {
+			t.Fatalf("writing %s: %v", overridePath, err)
+		}
+		t.Cleanup(func() {
+			os.Remove(overridePath)
+			core.InitializeColumnHeaders(resources.DefaultColumnHeadersJSON)
+		})
+	}
+	if err := core.InitializeColumnHeaders(resources.DefaultColumnHeadersJSON); err != nil {
+		t.Fatalf("InitializeColumnHeaders: %v", err)
+	}
+}
+
+func TestDetectColumns(t *testing.T) {
+	initColumnHeaders(t, "")
+	defaults := columnIndexes{
+		core.ColumnDepartment: 0, core.ColumnName: 1, core.ColumnID: 2, core.ColumnWeight: 3,
+		core.ColumnProductionDays: 4, core.ColumnSeniority: 5, core.ColumnMinHours: 6, core.ColumnMaxHours: 7,
+	}
+	tests := []struct {
+		name        string
+		header      []string
+		want        columnIndexes
+		wantErr     bool
+		wantWarning bool
+	}{
+		{
+			name:   "titles in file order",
+			header: []string{"نام واحد", "نام پرسنل", "کد پرسنلی"},
+			want:   columnIndexes{core.ColumnDepartment: 0, core.ColumnName: 1, core.ColumnID: 2},
+		},
+		{
+			name:   "reordered columns with optional ones",
+			header: []string{"کد پرسنلی", "سقف ساعت", "نام پرسنل", "", "واحد", "وزن"},
+			want:   columnIndexes{core.ColumnID: 0, core.ColumnMaxHours: 1, core.ColumnName: 2, core.ColumnDepartment: 4, core.ColumnWeight: 5},
+		},
+		{
+			name:   "synonyms",
+			header: []string{"واحد سازمانی", "نام و نام خانوادگی", "شماره پرسنلی", "ضریب", "سنوات", "حداکثر ساعت"},
+			want: columnIndexes{
+				core.ColumnDepartment: 0, core.ColumnName: 1, core.ColumnID: 2,
+				core.ColumnWeight: 3, core.ColumnSeniority: 4, core.ColumnMaxHours: 5,
+			},
+		},
+		{
+			name:   "english headers in any case",
+			header: []string{"Department", "NAME", "Personnel ID"},
+			want:   columnIndexes{core.ColumnDepartment: 0, core.ColumnName: 1, core.ColumnID: 2},
+		},
+		{
+			name:   "extra spaces, zero-width non-joiner and arabic letters",
+			header: []string{"  نام   واحد ", "نام\u200cپرسنل", "كد پرسنلي", "\u200fروزهای\u200c کارکرد\u200e"},
+			want:   columnIndexes{core.ColumnDepartment: 0, core.ColumnName: 1, core.ColumnID: 2, core.ColumnProductionDays: 3},
+		},
+		{
+			name:   "first matching column wins",
+			header: []string{"نام واحد", "نام پرسنل", "نام", "کد پرسنلی"},
+			want:   columnIndexes{core.ColumnDepartment: 0, core.ColumnName: 1, core.ColumnID: 3},
+		},
+		{
+			name:        "no recognized header falls back to default indexes",
+			header:      []string{"ستون ۱", "ستون ۲", "ستون ۳"},
+			want:        defaults,
+			wantWarning: true,
+		},
+		{
+			name:        "empty header row falls back to default indexes",
+			header:      nil,
+			want:        defaults,
+			wantWarning: true,
+		},
+		{
+			name:    "missing required column",
+			header:  []string{"نام واحد", "نام پرسنل", "وزن"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &Report{}
+			got, err := detectColumns(tt.header, "Sheet1", 1, report)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("detectColumns(%q) = %v, want error", tt.header, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("detectColumns(%q) error: %v", tt.header, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectColumns(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+			if _, warnings := report.Counts(); (warnings > 0) != tt.wantWarning {
+				t.Errorf("report warnings = %d, want warning: %v (%v)", warnings, tt.wantWarning, report.Issues)
+			}
+		})
+	}
+}
+
+func TestDetectColumnsOverrideSynonyms(t *testing.T) {
+	initColumnHeaders(t, `{"columns": [
+		{"key": "id", "synonyms": ["کد ملی کارمند"]},
+		{"key": "department", "synonyms": ["قسمت"]}
+	]}`)
+
+	got, err := detectColumns([]string{"قسمت", "نام پرسنل", "کد ملی کارمند"}, "Sheet1", 1, &Report{})
+	if err != nil {
+		t.Fatalf("detectColumns error: %v", err)
+	}
+	want := columnIndexes{core.ColumnDepartment: 0, core.ColumnName: 1, core.ColumnID: 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("detectColumns = %v, want %v", got, want)
+	}
+
+	// مترادف‌های پیش‌فرض همچنان پذیرفته می‌شوند
+	if _, err := detectColumns([]string{"واحد", "نام", "کد پرسنلی"}, "Sheet1", 1, &Report{}); err != nil {
+		t.Errorf("default synonyms rejected after override: %v", err)
+	}
+}
@@ -4,16 +4,72 @@ import (
 	"fmt"
 	"io"
 	"overtime_go/core" // اطمینان از صحت نام ماژول
-	"strconv"
 	"strings"
 
 	"github.com/xuri/excelize/v2"
 )
 
-func ReadBasicDataFromExcel(filePath string) (totalHours, prodDays int, monthName string, err error) {
+// layoutSheet نام شیت اطلاعات فایل را بر اساس قالب برمی‌گرداند؛ شیت خالی در قالب یعنی اولین شیت فایل.
+func layoutSheet(f *excelize.File, filePath string, profile core.LayoutProfile) (string, error) {
+	sheetList := f.GetSheetList()
+	if len(sheetList) == 0 {
+		return "", fmt.Errorf("فایل اکسل هیچ شیتی ندارد: %s", filePath)
+	}
+	if profile.SheetName == "" {
+		return sheetList[0], nil
+	}
+	for _, sheet := range sheetList {
+		if strings.EqualFold(strings.TrimSpace(sheet), strings.TrimSpace(profile.SheetName)) {
+			return sheet, nil
+		}
+	}
+	return "", fmt.Errorf("شیت '%s' (قالب '%s') در فایل %s یافت نشد", profile.SheetName, profile.Resolved().Name, filePath)
+}
+
+// resolveCellRef آدرس سلول (مانند "F1") یا نام محدوده تعریف شده در فایل را به شیت و آدرس سلول تبدیل می‌کند.
+// برای محدوده چند سلولی، اولین سلول ملاک است.
+func resolveCellRef(f *excelize.File, sheetName, ref string) (string, string, error) {
+	ref = strings.TrimSpace(ref)
+	if _, _, err := excelize.CellNameToCoordinates(ref); err == nil {
+		return sheetName, ref, nil
+	}
+	for _, definedName := range f.GetDefinedName() {
+		if !strings.EqualFold(definedName.Name, ref) {
+			continue
+		}
+		// RefersTo به شکل 'Sheet1'!$F$1 یا Sheet1!$F$1:$F$3 است
+		refersTo := strings.TrimPrefix(definedName.RefersTo, "=")
+		sep := strings.LastIndex(refersTo, "!")
+		if sep < 0 {
+			return "", "", fmt.Errorf("محدوده '%s' به سلول مشخصی اشاره نمی‌کند (%s)", ref, definedName.RefersTo)
+		}
+		cell := strings.ReplaceAll(refersTo[sep+1:], "$", "")
+		if colon := strings.Index(cell, ":"); colon >= 0 {
+			cell = cell[:colon]
+		}
+		return strings.Trim(refersTo[:sep], "'"), cell, nil
+	}
+	return "", "", fmt.Errorf("'%s' نه آدرس سلول معتبر است و نه نام محدوده تعریف شده در فایل", ref)
+}
+
+// readLayoutCell مقدار سلول یا محدوده ref قالب را به همراه شیت و آدرس سلول خوانده شده برمی‌گرداند.
+func readLayoutCell(f *excelize.File, sheetName, ref string) (value, cellSheet, cell string, err error) {
+	cellSheet, cell, err = resolveCellRef(f, sheetName, ref)
+	if err != nil {
+		return "", "", "", err
+	}
+	value, err = f.GetCellValue(cellSheet, cell)
+	return value, cellSheet, cell, err
+}
+
+// ReadBasicDataFromExcel سرانه، روزهای تولید و دوره (سال و ماه شمسی) را از سلول‌های قالب فایل (پیش‌فرض F1 تا F3
+// اولین شیت) می‌خواند. مقدار خالی یا نامعتبر صفر و دوره نامعتبر به صورت دوره صفر (Period.IsZero) برگردانده
+// و علت آن با آدرس سلول در report ثبت می‌شود؛ خطا فقط برای باز نشدن فایل یا شیت و سلول ناموجود در قالب است.
+func ReadBasicDataFromExcel(filePath string, profile core.LayoutProfile, report *Report) (totalHours, prodDays int, period core.Period, err error) {
+	profile = profile.Resolved()
 	f, err := excelize.OpenFile(filePath)
 	if err != nil {
-		return 0, 0, "", fmt.Errorf("خطا در باز کردن فایل اکسل %s: %w", filePath, err)
+		return 0, 0, core.Period{}, fmt.Errorf("خطا در باز کردن فایل اکسل %s: %w", filePath, err)
 	}
 	defer func() {
 		if err := f.Close(); err != nil {
@@ -21,72 +77,82 @@ func ReadBasicDataFromExcel(filePath string) (totalHours, prodDays int, monthNam
 		}
 	}()
 
-	// از اولین شیت استفاده می‌کنیم
-	sheetList := f.GetSheetList()
-	if len(sheetList) == 0 {
-		return 0, 0, "", fmt.Errorf("فایل اکسل هیچ شیتی ندارد: %s", filePath)
+	sheetName, err := layoutSheet(f, filePath, profile)
+	if err != nil {
+		return 0, 0, core.Period{}, err
 	}
-	sheetName := sheetList[0]
 
-	seranehValStr, errGetSeraneh := f.GetCellValue(sheetName, core.SeranehCell)
-	if errGetSeraneh != nil {
-		// fmt.Printf("هشدار: خطا در خواندن سلول سرانه %s از شیت '%s': %v. مقدار صفر در نظر گرفته شد.\n", core.SeranehCell, sheetName, errGetSeraneh)
-		totalHours = 0 // اگر خطا بود یا خالی بود، صفر در نظر بگیر
-	} else {
-		// تلاش برای تبدیل به عدد، حتی اگر رشته باشد (مثلا "۱۲۳")
-		// excelize ممکن است اعداد را به صورت رشته برگرداند اگر فرمت سلول Text باشد.
-		parsedVal, errParse := strconv.ParseFloat(strings.TrimSpace(seranehValStr), 64)
-		if errParse == nil && parsedVal >= 0 {
-			totalHours = int(parsedVal)
-		} else {
-			// fmt.Printf("هشدار: مقدار سرانه '%s' در سلول %s شیت '%s' نامعتبر است. مقدار صفر در نظر گرفته شد.\n", seranehValStr, core.SeranehCell, sheetName)
-			totalHours = 0
+	// excelize ممکن است اعداد را به صورت رشته برگرداند اگر فرمت سلول Text باشد؛ ارقام فارسی مانند "۱۲۰" و
+	// جداکننده هزارگان مانند "۱٬۲۰۰" با parseNumber خوانده می‌شوند.
+	readNonNegative := func(ref, title string) (int, error) {
+		text, cellSheet, cell, errCell := readLayoutCell(f, sheetName, ref)
+		if errCell != nil {
+			return 0, fmt.Errorf("خطا در خواندن %s از '%s' (قالب '%s'): %w", title, ref, profile.Name, errCell)
+		}
+		text = strings.TrimSpace(text)
+		if normalizeNumber(text) == "" {
+			report.addWarning(cellSheet, cell, 0, "%s خالی است؛ صفر در نظر گرفته شد.", title)
+			return 0, nil
+		}
+		parsedVal, errParse := parseNumber(text)
+		if errParse != nil || parsedVal < 0 {
+			report.addError(cellSheet, cell, 0, "مقدار %s '%s' عدد غیرمنفی نیست؛ صفر در نظر گرفته شد.", title, text)
+			return 0, nil
+		}
+		if parsedVal != float64(int(parsedVal)) {
+			report.addWarning(cellSheet, cell, 0, "%s '%s' عدد صحیح نیست؛ %d در نظر گرفته شد.", title, text, int(parsedVal))
 		}
+		return int(parsedVal), nil
+	}
+	if totalHours, err = readNonNegative(profile.TotalHoursCell, "سرانه"); err != nil {
+		return 0, 0, core.Period{}, err
+	}
+	if prodDays, err = readNonNegative(profile.ProductionDaysCell, "روزهای تولید"); err != nil {
+		return 0, 0, core.Period{}, err
 	}
 
-	prodDaysValStr, errGetProd := f.GetCellValue(sheetName, core.ProductionDaysCell)
-	if errGetProd != nil {
-		// fmt.Printf("هشدار: خطا در خواندن سلول روزهای تولید %s از شیت '%s': %v. مقدار صفر در نظر گرفته شد.\n", core.ProductionDaysCell, sheetName, errGetProd)
-		prodDays = 0
+	monthVal, monthSheet, monthCell, err := readLayoutCell(f, sheetName, profile.MonthCell)
+	if err != nil {
+		return 0, 0, core.Period{}, fmt.Errorf("خطا در خواندن ماه از '%s' (قالب '%s'): %w", profile.MonthCell, profile.Name, err)
+	}
+	// قالب‌هایی مانند "۱۴۰۴/۰۱"، "فروردین ۱۴۰۴" یا فقط نام ماه پذیرفته می‌شوند.
+	if strings.TrimSpace(monthVal) == "" {
+		report.addWarning(monthSheet, monthCell, 0, "ماه تخصیص خالی است؛ ماه جاری سیستم استفاده می‌شود.")
+	} else if parsedPeriod, errParse := core.ParsePeriod(monthVal); errParse != nil {
+		report.addError(monthSheet, monthCell, 0, "ماه تخصیص '%s' نامعتبر است (%v)؛ ماه جاری سیستم استفاده می‌شود.", monthVal, errParse)
 	} else {
-		parsedVal, errParse := strconv.ParseFloat(strings.TrimSpace(prodDaysValStr), 64)
-		if errParse == nil && parsedVal >= 0 {
-			prodDays = int(parsedVal)
-		} else {
-			// fmt.Printf("هشدار: مقدار روزهای تولید '%s' در سلول %s شیت '%s' نامعتبر است. مقدار صفر در نظر گرفته شد.\n", prodDaysValStr, core.ProductionDaysCell, sheetName)
-			prodDays = 0
-		}
+		period = parsedPeriod
 	}
+	return totalHours, prodDays, period, nil
+}
 
-	monthNameVal, errGetMonth := f.GetCellValue(sheetName, core.MonthCell)
-	if errGetMonth != nil {
-		// fmt.Printf("هشدار: خطا در خواندن سلول ماه %s از شیت '%s': %v.\n", core.MonthCell, sheetName, errGetMonth)
-		monthName = ""
-	} else {
-		monthName = strings.TrimSpace(monthNameVal)
-		isValidMonth := false
-		for _, m := range core.PersianMonthNames {
-			if m == monthName {
-				isValidMonth = true
-				break
-			}
-		}
-		if !isValidMonth {
-			// fmt.Printf("هشدار: نام ماه '%s' در سلول %s شیت '%s' نامعتبر است.\n", monthName, core.MonthCell, sheetName)
-			monthName = ""
-		}
+// employeeTable جدول پرسنل شیت قالب است. rows فقط ردیف‌های بعد از هدر هستند و firstRow شماره ردیف اکسل اولین آن‌ها است.
+type employeeTable struct {
+	sheet    string
+	columns  columnIndexes
+	rows     [][]string
+	firstRow int
+}
+
+// cellRef آدرس سلول ستون key در ردیف i جدول (مانند "C12") را برمی‌گرداند.
+func (t employeeTable) cellRef(key string, i int) string {
+	idx, ok := t.columns[key]
+	if !ok {
+		return ""
+	}
+	cell, err := excelize.CoordinatesToCellName(idx+1, t.firstRow+i)
+	if err != nil {
+		return ""
 	}
-	// برگرداندن nil برای خطا اگر هیچ خطای بحرانی رخ نداده باشد
-	// و فقط مقادیر پیش‌فرض استفاده شده باشند.
-	// اگر می‌خواهید برای مقادیر نامعتبر هم خطا برگردانید، باید اینجا خطا را تنظیم کنید.
-	// فعلا، اگر فایل باز شود، خطا nil است مگر اینکه خود excelize خطا بدهد.
-	return totalHours, prodDays, monthName, nil
+	return cell
 }
 
-func ReadEmployeesFromExcel(filePath string, departmentShiftFilter string) ([]core.Employee, error) {
+// readEmployeeTable ردیف‌های جدول پرسنل شیت قالب را می‌خواند و ستون‌ها را از ردیف هدر قالب تشخیص می‌دهد.
+func readEmployeeTable(filePath string, profile core.LayoutProfile, report *Report) (employeeTable, error) {
+	profile = profile.Resolved()
 	f, err := excelize.OpenFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("خطا در باز کردن فایل اکسل %s: %w", filePath, err)
+		return employeeTable{}, fmt.Errorf("خطا در باز کردن فایل اکسل %s: %w", filePath, err)
 	}
 	defer func() {
 		if err := f.Close(); err != nil {
@@ -94,63 +160,190 @@ func ReadEmployeesFromExcel(filePath string, departmentShiftFilter string) ([]co
 		}
 	}()
 
-	sheetList := f.GetSheetList()
-	if len(sheetList) == 0 {
-		return nil, fmt.Errorf("فایل اکسل هیچ شیتی ندارد: %s", filePath)
+	sheetName, err := layoutSheet(f, filePath, profile)
+	if err != nil {
+		return employeeTable{}, err
 	}
-	sheetName := sheetList[0]
-
 	rows, err := f.GetRows(sheetName)
 	if err != nil {
-		return nil, fmt.Errorf("خطا در خواندن ردیف‌ها از شیت '%s' در فایل %s: %w", sheetName, filePath, err)
+		return employeeTable{}, fmt.Errorf("خطا در خواندن ردیف‌ها از شیت '%s' در فایل %s: %w", sheetName, filePath, err)
+	}
+	if len(rows) < profile.HeaderRow {
+		return employeeTable{}, fmt.Errorf("ردیف هدر %d (قالب '%s') در شیت '%s' فایل %s وجود ندارد", profile.HeaderRow, profile.Name, sheetName, filePath)
+	}
+	columns, err := detectColumns(rows[profile.HeaderRow-1], sheetName, profile.HeaderRow, report)
+	if err != nil {
+		return employeeTable{}, err
 	}
+	return employeeTable{sheet: sheetName, columns: columns, rows: rows[profile.HeaderRow:], firstRow: profile.HeaderRow + 1}, nil
+}
 
-	var employees []core.Employee
-	if len(rows) <= 1 {
-		return employees, nil
+// ReadEmployeesFromExcel پرسنل واحد-شیفت departmentShiftFilter را از جدول پرسنل شیت قالب می‌خواند. ردیف‌هایی که
+// به دلیل نام یا کد پرسنلی خالی کنار گذاشته می‌شوند و مقادیر نامعتبر ستون‌های اختیاری در report ثبت می‌شوند.
+func ReadEmployeesFromExcel(filePath string, departmentShiftFilter string, profile core.LayoutProfile, report *Report) ([]core.Employee, error) {
+	table, err := readEmployeeTable(filePath, profile, report)
+	if err != nil {
+		return nil, err
 	}
 
-	for rIdx, row := range rows {
-		if rIdx == 0 { // نادیده گرفتن ردیف هدر
+	var employees []core.Employee
+	seenIDs := make(map[string]int)
+	for i, row := range table.rows {
+		rowNumber := table.firstRow + i
+		deptFromFile := table.columns.cell(row, core.ColumnDepartment)
+		name := table.columns.cell(row, core.ColumnName)
+		id := normalizeID(table.columns.cell(row, core.ColumnID))
+		if deptFromFile == "" && (name != "" || id != "") {
+			report.addWarning(table.sheet, table.cellRef(core.ColumnDepartment, i), rowNumber, "نام واحد ردیف '%s' (%s) خالی است؛ این ردیف در هیچ واحدی وارد نمی‌شود.", name, id)
 			continue
 		}
-		if len(row) <= core.EmployeeDataColID { // حداقل باید ستون‌های مورد نیاز وجود داشته باشند
-			// fmt.Printf("ردیف %d در شیت '%s' تعداد ستون کافی ندارد (نیاز به %d، موجود %d).\n", rIdx+1, sheetName, core.EmployeeDataColID+1, len(row))
+		if !strings.EqualFold(deptFromFile, departmentShiftFilter) {
 			continue
 		}
 
-		deptFromFile := ""
-		if len(row) > core.EmployeeDataColDept { // بررسی وجود ستون
-			deptFromFile = strings.TrimSpace(row[core.EmployeeDataColDept])
+		switch {
+		case name == "" || name == "نامشخص":
+			report.addError(table.sheet, table.cellRef(core.ColumnName, i), rowNumber, "نام پرسنل با کد '%s' خالی یا نامشخص است؛ ردیف وارد نشد.", id)
+			continue
+		case id == "" || id == "0000":
+			report.addError(table.sheet, table.cellRef(core.ColumnID, i), rowNumber, "کد پرسنلی '%s' برای '%s' خالی یا نامعتبر است؛ ردیف وارد نشد.", id, name)
+			continue
+		}
+		if firstRow, duplicate := seenIDs[id]; duplicate {
+			report.addWarning(table.sheet, table.cellRef(core.ColumnID, i), rowNumber, "کد پرسنلی '%s' ('%s') تکراری است (ردیف %d).", id, name, firstRow)
+		} else {
+			seenIDs[id] = rowNumber
 		}
 
-		if !strings.EqualFold(deptFromFile, departmentShiftFilter) {
-			continue
+		optional := func(key, title string) float64 {
+			text := table.columns.cell(row, key)
+			val, ok := optionalFloatCell(text)
+			if !ok {
+				report.addWarning(table.sheet, table.cellRef(key, i), rowNumber, "مقدار %s '%s' برای '%s' نامعتبر است؛ صفر در نظر گرفته شد.", title, text, name)
+			}
+			return val
+		}
+		employees = append(employees, core.Employee{
+			Name:           name,
+			ID:             id,
+			Hours:          0,
+			Locked:         false,
+			Weight:         optional(core.ColumnWeight, "وزن"),
+			ProductionDays: int(optional(core.ColumnProductionDays, "روزهای کارکرد")),
+			Seniority:      int(optional(core.ColumnSeniority, "سابقه")),
+			MinHours:       int(optional(core.ColumnMinHours, "حداقل ساعت")),
+			MaxHours:       int(optional(core.ColumnMaxHours, "سقف ساعت")),
+		})
+	}
+	return employees, nil
+}
+
+// findBudgetSheet نام شیت سرانه واحدها (یکی از core.BudgetSheetNames) را برمی‌گرداند؛ رشته خالی یعنی فایل شیت سرانه ندارد.
+func findBudgetSheet(f *excelize.File) string {
+	for _, sheet := range f.GetSheetList() {
+		normalized := strings.TrimSpace(core.NormalizePersianText(sheet))
+		for _, name := range core.BudgetSheetNames {
+			if strings.EqualFold(normalized, name) {
+				return sheet
+			}
+		}
+	}
+	return ""
+}
+
+// ReadBudgetSheet سرانه، روزهای تولید و ماه هر واحد-شیفت را از شیت سرانه واحدها می‌خواند. found در صورت نبود این شیت false است.
+// این شیت جدا از قالب (LayoutProfile) فایل، چیدمان ثابتی دارد: ردیف اول هدر و ستون A واحد-شیفت، B سرانه، C روزهای
+// تولید و D ماه. ردیفی که عدد یا ماه نامعتبر دارد یا واحد آن تکراری است با آدرس سلول در report ثبت و کنار گذاشته
+// می‌شود؛ خطا فقط برای باز نشدن فایل یا شیت است.
+func ReadBudgetSheet(filePath string, report *Report) (budgets map[string]core.DepartmentBudget, found bool, err error) {
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return nil, false, fmt.Errorf("خطا در باز کردن فایل اکسل %s: %w", filePath, err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			fmt.Printf("خطا در بستن فایل اکسل %s: %v\n", filePath, err)
 		}
+	}()
 
-		name := ""
-		if len(row) > core.EmployeeDataColName {
-			name = strings.TrimSpace(row[core.EmployeeDataColName])
+	sheetName := findBudgetSheet(f)
+	if sheetName == "" {
+		return nil, false, nil
+	}
+	rows, err := f.GetRows(sheetName)
+	if err != nil {
+		return nil, true, fmt.Errorf("خطا در خواندن ردیف‌ها از شیت '%s' در فایل %s: %w", sheetName, filePath, err)
+	}
+
+	budgets = make(map[string]core.DepartmentBudget)
+	firstRows := make(map[string]int)
+	cellRef := func(col, rowNumber int) string {
+		cell, errCell := excelize.CoordinatesToCellName(col+1, rowNumber)
+		if errCell != nil {
+			return ""
+		}
+		return cell
+	}
+	readInt := func(row []string, col, rowNumber int, deptShift, title string) (int, bool) {
+		if len(row) <= col || normalizeNumber(row[col]) == "" {
+			return 0, true
+		}
+		val, errParse := parseNumber(row[col])
+		if errParse != nil || val < 0 {
+			report.addError(sheetName, cellRef(col, rowNumber), rowNumber, "مقدار %s '%s' برای واحد '%s' عدد غیرمنفی نیست؛ ردیف وارد نشد.", title, row[col], deptShift)
+			return 0, false
+		}
+		if val != float64(int(val)) {
+			report.addWarning(sheetName, cellRef(col, rowNumber), rowNumber, "%s '%s' برای واحد '%s' عدد صحیح نیست؛ %d در نظر گرفته شد.", title, row[col], deptShift, int(val))
 		}
-		id := ""
-		if len(row) > core.EmployeeDataColID {
-			id = strings.TrimSpace(row[core.EmployeeDataColID])
+		return int(val), true
+	}
+	for rIdx, row := range rows {
+		if rIdx == 0 || len(row) <= core.BudgetColDeptShift { // نادیده گرفتن ردیف هدر و ردیف‌های خالی
+			continue
+		}
+		rowNumber := rIdx + 1
+		deptShift := strings.TrimSpace(row[core.BudgetColDeptShift])
+		if deptShift == "" {
+			continue
+		}
+		if firstRow, duplicate := firstRows[deptShift]; duplicate {
+			report.addError(sheetName, cellRef(core.BudgetColDeptShift, rowNumber), rowNumber, "واحد '%s' تکراری است (ردیف %d)؛ این ردیف وارد نشد.", deptShift, firstRow)
+			continue
 		}
+		firstRows[deptShift] = rowNumber
 
-		if name == "" || id == "" || name == "نامشخص" || id == "0000" {
-			// fmt.Printf("رد شدن از ردیف با اطلاعات ناقص در شیت '%s': Name='%s', ID='%s'\n", sheetName, name, id)
+		var budget core.DepartmentBudget
+		var okHours, okDays bool
+		budget.TotalHours, okHours = readInt(row, core.BudgetColTotalHours, rowNumber, deptShift, "سرانه")
+		budget.ProductionDays, okDays = readInt(row, core.BudgetColProductionDays, rowNumber, deptShift, "روزهای تولید")
+		if !okHours || !okDays {
 			continue
 		}
+		if len(row) > core.BudgetColPeriod && strings.TrimSpace(row[core.BudgetColPeriod]) != "" {
+			period, errParse := core.ParsePeriod(row[core.BudgetColPeriod])
+			if errParse != nil {
+				report.addError(sheetName, cellRef(core.BudgetColPeriod, rowNumber), rowNumber, "ماه '%s' برای واحد '%s' نامعتبر است (%v)؛ ردیف وارد نشد.", row[core.BudgetColPeriod], deptShift, errParse)
+				continue
+			}
+			budget.Period = period
+		}
+		budgets[deptShift] = budget
+	}
+	return budgets, true, nil
+}
 
-		employees = append(employees, core.Employee{
-			Name:      name,
-			ID:        id,
-			Hours:     0,
-			Locked:    false,
-			MonthType: "",
-		})
+// optionalFloatCell مقدار عددی یک ستون اختیاری را می‌خواند؛ مقدار خالی صفر است و ok برای مقدار
+// غیرعددی یا منفی false است (که آن هم صفر در نظر گرفته می‌شود).
+func optionalFloatCell(text string) (float64, bool) {
+	if normalizeNumber(text) == "" {
+		return 0, true
 	}
-	return employees, nil
+	val, err := parseNumber(text)
+	if err != nil || val < 0 {
+		return 0, false
+	}
+	return val, true
 }
 
 func WriteDataToExcel(writer io.Writer, data [][]interface{}) error {
@@ -184,37 +377,17 @@ func WriteDataToExcel(writer io.Writer, data [][]interface{}) error {
 	return nil
 }
 
-func GetUniqueDepartmentsFromFile(filePath string) ([]string, error) {
-	f, err := excelize.OpenFile(filePath)
+// GetUniqueDepartmentsFromFile نام واحد-شیفت‌های موجود در ستون واحد جدول پرسنل را (به ترتیب اولین ظهور) برمی‌گرداند.
+func GetUniqueDepartmentsFromFile(filePath string, profile core.LayoutProfile, report *Report) ([]string, error) {
+	table, err := readEmployeeTable(filePath, profile, report)
 	if err != nil {
-		return nil, fmt.Errorf("خطا در باز کردن فایل اکسل %s: %w", filePath, err)
+		return nil, err
 	}
-	defer func() {
-		if err := f.Close(); err != nil {
-			fmt.Printf("خطا در بستن فایل اکسل %s: %v\n", filePath, err)
-		}
-	}()
 
-	sheetList := f.GetSheetList()
-	if len(sheetList) == 0 {
-		return nil, fmt.Errorf("فایل اکسل هیچ شیتی ندارد: %s", filePath)
-	}
-	sheetName := sheetList[0]
-
-	cols, err := f.GetCols(sheetName)
-	if err != nil {
-		return nil, fmt.Errorf("خطا در خواندن ستون‌ها از شیت '%s' فایل %s: %w", sheetName, filePath, err)
-	}
-	if len(cols) == 0 || len(cols) <= core.EmployeeDataColDept { // باید حداقل ستون دپارتمان وجود داشته باشد
-		return nil, fmt.Errorf("ستون نام واحد (اندیس %d) در شیت '%s' فایل %s یافت نشد یا خالی است.", core.EmployeeDataColDept, sheetName, filePath)
-	}
-
-	firstCol := cols[core.EmployeeDataColDept]
 	uniqueDeptsMap := make(map[string]bool)
 	var uniqueDeptsList []string
-
-	for i := 1; i < len(firstCol); i++ { // از ردیف دوم (اندیس 1) شروع می‌کنیم
-		deptName := strings.TrimSpace(firstCol[i])
+	for _, row := range table.rows {
+		deptName := table.columns.cell(row, core.ColumnDepartment)
 		if deptName != "" && !uniqueDeptsMap[deptName] {
 			uniqueDeptsMap[deptName] = true
 			uniqueDeptsList = append(uniqueDeptsList, deptName)
@@ -0,0 +1,35 @@
+package excel
+
+import (
+	"strconv"
+	"strings"
+
+	"overtime_go/core"
+)
+
+// normalizeNumber متن یک سلول عددی را برای strconv آماده می‌کند: ارقام فارسی و عربی و نویسه‌های کنترل جهت متن
+// با core.NormalizePersianText، ممیز فارسی "٫" به "." و جداکننده‌های هزارگان ("٬" و ",") و فاصله‌ها حذف می‌شوند.
+func normalizeNumber(text string) string {
+	var b strings.Builder
+	for _, r := range core.NormalizePersianText(text) {
+		switch r {
+		case '٫':
+			b.WriteRune('.')
+		case '٬', ',', ' ', '\u00a0', '\u202f', '\u200c': // جداکننده هزارگان، فاصله‌های نشکن و نیم‌فاصله
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// parseNumber مقدار عددی سلولی را که ممکن است با ارقام فارسی یا عربی و جداکننده هزارگان نوشته شده باشد می‌خواند.
+func parseNumber(text string) (float64, error) {
+	return strconv.ParseFloat(normalizeNumber(text), 64)
+}
+
+// normalizeID کد پرسنلی را با ارقام لاتین و بدون نویسه‌های کنترل جهت متن برمی‌گرداند تا کد "۱۲۳" فایل
+// با کد "123" ذخیره شده یکسان باشد.
+func normalizeID(text string) string {
+	return strings.TrimSpace(core.NormalizePersianText(text))
+}
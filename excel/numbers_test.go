@@ -0,0 +1,78 @@
+package excel
+
+import "testing"
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{name: "latin digits", input: "120", want: 120},
+		{name: "persian digits", input: "۱۲۰", want: 120},
+		{name: "arabic-indic digits", input: "١٢٠", want: 120},
+		{name: "persian decimal separator", input: "۱۲٫۵", want: 12.5},
+		{name: "latin decimal point", input: "12.5", want: 12.5},
+		{name: "persian thousands separator", input: "۱٬۲۰۰", want: 1200},
+		{name: "comma thousands separator", input: "1,200", want: 1200},
+		{name: "thousands and decimal", input: "۱٬۲۰۰٫۷۵", want: 1200.75},
+		{name: "surrounding spaces", input: "  ۸۰  ", want: 80},
+		{name: "non-breaking space separator", input: "1\u00a0200", want: 1200},
+		{name: "bidi marks", input: "\u200f۴۵\u200e", want: 45},
+		{name: "negative", input: "-۵", want: -5},
+		{name: "empty", input: "", wantErr: true},
+		{name: "only spaces", input: "   ", wantErr: true},
+		{name: "text", input: "ده", wantErr: true},
+		{name: "mixed text", input: "۱۲ ساعت", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNumber(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseNumber(%q) = %v, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseNumber(%q) error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseNumber(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeNumber(t *testing.T) {
+	tests := map[string]string{
+		"":          "",
+		"  ":        "",
+		"۱٬۲۳۴٫۵":   "1234.5",
+		"1, 234":    "1234",
+		" ٠٫٢٥ ":    "0.25",
+		"۱۲\u200c۰": "120",
+	}
+	for input, want := range tests {
+		if got := normalizeNumber(input); got != want {
+			t.Errorf("normalizeNumber(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestNormalizeID(t *testing.T) {
+	tests := map[string]string{
+		"۰۰۱۲۳":           "00123",
+		"٠٠٤٥":            "0045",
+		"  00123  ":       "00123",
+		"\u200f۰۰۷\u200e": "007",
+		"A-۱۲":            "A-12",
+		"":                "",
+	}
+	for input, want := range tests {
+		if got := normalizeID(input); got != want {
+			t.Errorf("normalizeID(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
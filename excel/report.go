@@ -0,0 +1,99 @@
+package excel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity سطح اهمیت یک مورد گزارش خواندن فایل است.
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "خطا"
+	}
+	return "هشدار"
+}
+
+// Issue یک مورد گزارش خواندن فایل اکسل است. Cell (مانند "F1" یا "C12") و Row (شماره ردیف در اکسل)
+// در صورت مشخص نبودن خالی و صفر هستند.
+type Issue struct {
+	Severity Severity
+	Sheet    string
+	Cell     string
+	Row      int
+	Message  string
+}
+
+// Location محل مورد را به شکل "شیت 'Sheet1'، سلول C12" برمی‌گرداند.
+func (i Issue) Location() string {
+	var parts []string
+	if i.Sheet != "" {
+		parts = append(parts, fmt.Sprintf("شیت '%s'", i.Sheet))
+	}
+	if i.Cell != "" {
+		parts = append(parts, "سلول "+i.Cell)
+	} else if i.Row > 0 {
+		parts = append(parts, fmt.Sprintf("ردیف %d", i.Row))
+	}
+	return strings.Join(parts, "، ")
+}
+
+func (i Issue) String() string {
+	if location := i.Location(); location != "" {
+		return fmt.Sprintf("%s (%s): %s", i.Severity, location, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+}
+
+// Report هشدارها و خطاهای خواندن فایل اکسل است تا پیش از اعمال داده‌ها به کاربر نمایش داده شود.
+// توابع خواندن، Report را به صورت اشاره‌گر می‌گیرند و nil یعنی گزارش لازم نیست.
+type Report struct {
+	Issues []Issue
+}
+
+func (r *Report) add(severity Severity, sheet, cell string, row int, format string, args ...interface{}) {
+	if r == nil {
+		return
+	}
+	issue := Issue{Severity: severity, Sheet: sheet, Cell: cell, Row: row, Message: fmt.Sprintf(format, args...)}
+	for _, existing := range r.Issues {
+		if existing == issue { // یک ردیف ممکن است برای چند واحد خوانده شود
+			return
+		}
+	}
+	r.Issues = append(r.Issues, issue)
+}
+
+func (r *Report) addWarning(sheet, cell string, row int, format string, args ...interface{}) {
+	r.add(SeverityWarning, sheet, cell, row, format, args...)
+}
+
+func (r *Report) addError(sheet, cell string, row int, format string, args ...interface{}) {
+	r.add(SeverityError, sheet, cell, row, format, args...)
+}
+
+// HasIssues مشخص می‌کند که گزارش هشدار یا خطایی دارد.
+func (r *Report) HasIssues() bool {
+	return r != nil && len(r.Issues) > 0
+}
+
+// Counts تعداد خطاها و هشدارهای گزارش را برمی‌گرداند.
+func (r *Report) Counts() (errors, warnings int) {
+	if r == nil {
+		return 0, 0
+	}
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
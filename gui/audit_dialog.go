@@ -0,0 +1,201 @@
+package gui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"overtime_go/audit"
+	"overtime_go/core"
+	"overtime_go/excel"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const auditFilterAll = "همه"
+
+var auditLogHeaders = []string{"ردیف", "زمان", "کاربر", "منبع", "واحد", "کد پرسنلی", "نام پرسنل", "مورد", "مقدار قبلی", "مقدار جدید"}
+
+type auditLogDialog struct {
+	parentWindow fyne.Window
+	entries      []audit.Entry
+	filtered     []audit.Entry
+	table        *widget.Table
+	countLabel   *widget.Label
+
+	userSelect     *widget.Select
+	deptSelect     *widget.Select
+	sourceSelect   *widget.Select
+	employeeFilter *widget.Entry
+}
+
+// ShowAuditLogDialog گزارش حسابرسی تغییرات را با امکان فیلتر و خروجی اکسل نمایش می‌دهد و
+// سالم بودن زنجیره هش ردیف‌ها را بررسی می‌کند.
+func ShowAuditLogDialog(parent fyne.Window) {
+	entries, err := audit.ReadAll()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("خطا در خواندن گزارش حسابرسی: %w", err), parent)
+		if len(entries) == 0 {
+			return
+		}
+	}
+	a := &auditLogDialog{parentWindow: parent, entries: entries}
+
+	integrityLabel := widget.NewLabel("در بررسی زنجیره گزارش حسابرسی مشکلی یافت نشد.")
+	if errVerify := audit.Verify(entries); errVerify != nil {
+		integrityLabel.SetText("هشدار دستکاری: " + errVerify.Error())
+		integrityLabel.Importance = widget.DangerImportance
+	}
+	integrityLabel.Wrapping = fyne.TextWrapWord
+
+	users := map[string]bool{}
+	depts := map[string]bool{}
+	for _, e := range entries {
+		users[e.Username] = true
+		depts[e.DepartmentShift] = true
+	}
+	a.userSelect = widget.NewSelect(withAllOption(users), func(string) { a.applyFilters() })
+	a.deptSelect = widget.NewSelect(withAllOption(depts), func(string) { a.applyFilters() })
+	sourceOptions := []string{auditFilterAll}
+	for _, src := range audit.Sources {
+		sourceOptions = append(sourceOptions, src.DisplayName())
+	}
+	a.sourceSelect = widget.NewSelect(sourceOptions, func(string) { a.applyFilters() })
+	a.employeeFilter = widget.NewEntry()
+	a.employeeFilter.SetPlaceHolder("کد یا نام پرسنل")
+	a.employeeFilter.OnChanged = func(string) { a.applyFilters() }
+
+	a.table = widget.NewTable(
+		func() (int, int) { return len(a.filtered) + 1, len(auditLogHeaders) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		a.updateCell,
+	)
+	columnWidths := []float32{60, 150, 100, 140, 220, 100, 160, 120, 110, 110}
+	for col, width := range columnWidths {
+		a.table.SetColumnWidth(col, width)
+	}
+	a.countLabel = widget.NewLabel("")
+
+	a.userSelect.SetSelected(auditFilterAll)
+	a.deptSelect.SetSelected(auditFilterAll)
+	a.sourceSelect.SetSelected(auditFilterAll)
+	a.applyFilters()
+
+	exportButton := widget.NewButtonWithIcon("خروجی اکسل", theme.DocumentSaveIcon(), a.onExport)
+	filters := container.NewGridWithColumns(4,
+		container.NewBorder(nil, nil, widget.NewLabel("کاربر:"), nil, a.userSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("واحد:"), nil, a.deptSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("منبع:"), nil, a.sourceSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("پرسنل:"), nil, a.employeeFilter),
+	)
+	top := container.NewVBox(integrityLabel, filters)
+	bottom := container.NewBorder(nil, nil, a.countLabel, exportButton)
+
+	d := dialog.NewCustom("گزارش حسابرسی تغییرات", "بستن", trackActivity(container.NewBorder(top, bottom, nil, nil, a.table)), parent)
+	d.Resize(fyne.NewSize(1200, 650))
+	d.Show()
+}
+
+func withAllOption(values map[string]bool) []string {
+	options := make([]string, 0, len(values))
+	for v := range values {
+		options = append(options, v)
+	}
+	sort.Strings(options)
+	return append([]string{auditFilterAll}, options...)
+}
+
+func (a *auditLogDialog) applyFilters() {
+	touchActivity()
+	if a.table == nil {
+		return
+	}
+	employeeText := strings.TrimSpace(core.NormalizePersianText(a.employeeFilter.Text))
+	a.filtered = a.filtered[:0]
+	// جدیدترین تغییرات اول نمایش داده می‌شوند
+	for i := len(a.entries) - 1; i >= 0; i-- {
+		e := a.entries[i]
+		if a.userSelect.Selected != auditFilterAll && e.Username != a.userSelect.Selected {
+			continue
+		}
+		if a.deptSelect.Selected != auditFilterAll && e.DepartmentShift != a.deptSelect.Selected {
+			continue
+		}
+		if a.sourceSelect.Selected != auditFilterAll && e.Source.DisplayName() != a.sourceSelect.Selected {
+			continue
+		}
+		if employeeText != "" && !strings.Contains(e.EmployeeID, employeeText) && !strings.Contains(e.EmployeeName, employeeText) {
+			continue
+		}
+		a.filtered = append(a.filtered, e)
+	}
+	a.countLabel.SetText(fmt.Sprintf("%d ردیف از %d", len(a.filtered), len(a.entries)))
+	a.table.Refresh()
+}
+
+func auditEntryRow(e audit.Entry) []string {
+	return []string{
+		fmt.Sprint(e.Seq), core.FormatJalaliDateTime(e.Timestamp), e.Username, e.Source.DisplayName(), e.DepartmentShift,
+		e.EmployeeID, e.EmployeeName, e.Field, e.OldValue, e.NewValue,
+	}
+}
+
+func (a *auditLogDialog) updateCell(id widget.TableCellID, template fyne.CanvasObject) {
+	label := template.(*widget.Label)
+	if id.Row == 0 {
+		label.SetText(auditLogHeaders[id.Col])
+		label.TextStyle = fyne.TextStyle{Bold: true}
+		label.Refresh()
+		return
+	}
+	label.TextStyle = fyne.TextStyle{}
+	if id.Row-1 >= len(a.filtered) {
+		label.SetText("")
+		return
+	}
+	label.SetText(auditEntryRow(a.filtered[id.Row-1])[id.Col])
+}
+
+func (a *auditLogDialog) onExport() {
+	if len(a.filtered) == 0 {
+		dialog.ShowInformation("خروجی اکسل", "ردیفی برای خروجی گرفتن وجود ندارد.", a.parentWindow)
+		return
+	}
+	fileSaveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, errDialog error) {
+		if errDialog != nil {
+			dialog.ShowError(errDialog, a.parentWindow)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+
+		header := make([]interface{}, 0, len(auditLogHeaders)+1)
+		for _, h := range auditLogHeaders {
+			header = append(header, h)
+		}
+		header = append(header, "هش")
+		dataForExcel := [][]interface{}{header}
+		for _, e := range a.filtered {
+			row := make([]interface{}, 0, len(header))
+			for _, v := range auditEntryRow(e) {
+				row = append(row, v)
+			}
+			row = append(row, e.Hash)
+			dataForExcel = append(dataForExcel, row)
+		}
+		if err := excel.WriteDataToExcel(writer, dataForExcel); err != nil {
+			dialog.ShowError(fmt.Errorf("خطا در ذخیره فایل اکسل: %w", err), a.parentWindow)
+			return
+		}
+		dialog.ShowInformation("موفقیت", "گزارش حسابرسی با موفقیت ذخیره شد:\n"+writer.URI().Path(), a.parentWindow)
+	}, a.parentWindow)
+	fileSaveDialog.SetFileName(fmt.Sprintf("گزارش حسابرسی - %s.xlsx", time.Now().Format("2006-01-02")))
+	fileSaveDialog.Show()
+}
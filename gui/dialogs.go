@@ -6,6 +6,7 @@ import (
 
 	// "path/filepath" // اگر نیاز به کار با مسیرها باشد
 	"sort"
+	"strconv"
 	"strings"
 
 	"overtime_go/cloud" // اطمینان از صحت نام ماژول
@@ -26,7 +27,7 @@ type cloudLinkManagerDialog struct {
 	parentWindow fyne.Window
 	linksTable   *widget.Table
 
-	editableLinks map[string]string
+	editableLinks map[string]core.CloudLink
 
 	onCloseCallback func(changed bool)
 
@@ -35,7 +36,7 @@ type cloudLinkManagerDialog struct {
 
 func CreateCloudLinkManagerDialog(app fyne.App, parent fyne.Window, onCloseCallback func(changed bool)) dialog.Dialog {
 	loadedLinks := cloud.LoadCloudLinks()
-	editableLinksMap := make(map[string]string)
+	editableLinksMap := make(map[string]core.CloudLink)
 	for k, v := range loadedLinks {
 		editableLinksMap[k] = v
 	}
@@ -59,15 +60,17 @@ func CreateCloudLinkManagerDialog(app fyne.App, parent fyne.Window, onCloseCallb
 		manager.createCell,
 		manager.updateCell,
 	)
-	manager.linksTable.SetColumnWidth(0, 280)
-	manager.linksTable.SetColumnWidth(1, 380)
+	manager.linksTable.SetColumnWidth(0, 260)
+	manager.linksTable.SetColumnWidth(1, 320)
+	manager.linksTable.SetColumnWidth(2, 130)
 
 	testLinkButton := widget.NewButtonWithIcon("تست لینک انتخاب شده", theme.SearchIcon(), manager.onTestSelectedLink)
 
 	buttonsTop := container.NewHBox(testLinkButton)
 
 	helpTextContent := fmt.Sprintf(`- لینک دانلود مستقیم فایل اکسل (e.g., Dropbox dl=1) را برای هر واحد ویرایش کنید.
-- لینک انتخاب شده را تست کنید (تست، سلول‌های %s, %s, %s را در فایل اکسل بررسی می‌کند).
+- لینک انتخاب شده را تست کنید (تست، سلول‌های سرانه، روزهای تولید و ماه را طبق قالب واحد - پیش‌فرض %s, %s, %s - در فایل اکسل بررسی می‌کند).
+- با دکمه ستون "قالب فایل"، شیت، آدرس سلول‌ها (یا نام محدوده‌ها) و ردیف هدر فایل هر واحد را تعیین کنید.
 - تغییرات در فایل cloud_links.json (کنار فایل اجرایی برنامه) ذخیره می‌شوند.`,
 		core.SeranehCell, core.ProductionDaysCell, core.MonthCell)
 
@@ -85,7 +88,7 @@ func CreateCloudLinkManagerDialog(app fyne.App, parent fyne.Window, onCloseCallb
 		"مدیریت لینک‌های دانلود سرور",
 		"ذخیره تغییرات",
 		"انصراف",
-		mainDialogContent,
+		trackActivity(mainDialogContent),
 		func(saveConfirmed bool) {
 			if saveConfirmed {
 				originalLinks := cloud.LoadCloudLinks()
@@ -133,7 +136,7 @@ func CreateCloudLinkManagerDialog(app fyne.App, parent fyne.Window, onCloseCallb
 }
 
 func (m *cloudLinkManagerDialog) tableLength() (rows, cols int) {
-	return len(m.sortedDisplayDepts), 2
+	return len(m.sortedDisplayDepts), 3
 }
 
 func (m *cloudLinkManagerDialog) createCell() fyne.CanvasObject {
@@ -157,12 +160,20 @@ func (m *cloudLinkManagerDialog) updateCell(id widget.TableCellID, template fyne
 		cellContainer.Objects = []fyne.CanvasObject{nameLabel}
 	case 1:
 		linkEntry := widget.NewEntry()
-		linkEntry.SetText(m.editableLinks[deptShiftName])
+		linkEntry.SetText(m.editableLinks[deptShiftName].URL)
 		linkEntry.Wrapping = fyne.TextTruncate
 		linkEntry.OnChanged = func(newLink string) {
-			m.editableLinks[deptShiftName] = newLink
+			touchActivity()
+			link := m.editableLinks[deptShiftName]
+			link.URL = newLink
+			m.editableLinks[deptShiftName] = link
 		}
 		cellContainer.Objects = []fyne.CanvasObject{linkEntry}
+	case 2:
+		layoutButton := widget.NewButton(m.editableLinks[deptShiftName].Layout.Resolved().Name, func() {
+			m.onEditLayout(deptShiftName)
+		})
+		cellContainer.Objects = []fyne.CanvasObject{layoutButton}
 	}
 	cellContainer.Refresh()
 }
@@ -186,7 +197,7 @@ func (m *cloudLinkManagerDialog) onTestSelectedLink() {
 		}
 
 		linkToTest, ok := m.editableLinks[selectedDept]
-		if !ok || linkToTest == "" {
+		if !ok || linkToTest.URL == "" {
 			dialog.ShowError(fmt.Errorf("لینکی برای واحد '%s' جهت تست یافت نشد.", selectedDept), m.parentWindow)
 			return
 		}
@@ -195,7 +206,8 @@ func (m *cloudLinkManagerDialog) onTestSelectedLink() {
 		progress.Show()
 		go func() {
 			defer progress.Hide()
-			downloadURL := cloud.ConvertToDownloadLink(linkToTest)
+			downloadURL := cloud.ConvertToDownloadLink(linkToTest.URL)
+			layout := linkToTest.Layout.Resolved()
 
 			tempFilePath, err := cloud.DownloadToTempFile(downloadURL, "test_link_mgr_*.xlsx")
 			if err != nil {
@@ -205,24 +217,96 @@ func (m *cloudLinkManagerDialog) onTestSelectedLink() {
 			}
 			defer os.Remove(tempFilePath)
 
-			_, _, monthF3, errExcel := excel.ReadBasicDataFromExcel(tempFilePath)
+			report := &excel.Report{}
+			_, _, periodF3, errExcel := excel.ReadBasicDataFromExcel(tempFilePath, layout, report)
+			if errExcel == nil {
+				if _, errEmployees := excel.ReadEmployeesFromExcel(tempFilePath, selectedDept, layout, report); errEmployees != nil {
+					errExcel = errEmployees
+				}
+			}
 			// fyne.CurrentApp().Driver().RunOnMain(func() {
 			if errExcel != nil {
 				errMsgDetails := fmt.Sprintf("جزئیات بررسی محتوا: %v.", errExcel)
-				if monthF3 == "" && (errExcel != nil && strings.Contains(errExcel.Error(), core.MonthCell)) {
-					errMsgDetails += fmt.Sprintf(" مقدار ماه (%s) نیز خوانده نشد یا نامعتبر بود.", core.MonthCell)
+				if periodF3.IsZero() && (errExcel != nil && strings.Contains(errExcel.Error(), layout.MonthCell)) {
+					errMsgDetails += fmt.Sprintf(" مقدار ماه (%s) نیز خوانده نشد یا نامعتبر بود.", layout.MonthCell)
 				}
 				// Replace dialog.ShowWarning with dialog.ShowInformation
-				dialog.ShowInformation("نتیجه تست (هشدار)", fmt.Sprintf("لینک برای واحد '%s' قابل دانلود است، اما محتوای فایل اکسل (سلول‌های F1,F2,F3) ممکن است مشکل داشته باشد یا خالی باشد.\n%s", selectedDept, errMsgDetails), m.parentWindow)
+				dialog.ShowInformation("نتیجه تست (هشدار)", fmt.Sprintf("لینک برای واحد '%s' قابل دانلود است، اما محتوای فایل اکسل (سلول‌های %s,%s,%s قالب '%s') ممکن است مشکل داشته باشد یا خالی باشد.\n%s", selectedDept, layout.TotalHoursCell, layout.ProductionDaysCell, layout.MonthCell, layout.Name, errMsgDetails), m.parentWindow)
 			} else {
-				finalMonthName := monthF3
 				warningMsg := ""
-				if finalMonthName == "" {
-					warningMsg = fmt.Sprintf("\nهشدار: مقدار ماه در سلول %s فایل اکسل خالی یا نامعتبر است.", core.MonthCell)
+				if periodF3.IsZero() {
+					warningMsg = fmt.Sprintf("\nهشدار: مقدار ماه در سلول %s فایل اکسل خالی یا نامعتبر است.", layout.MonthCell)
+				} else {
+					warningMsg = fmt.Sprintf("\nدوره خوانده شده: %s", periodF3)
+				}
+				dialog.ShowInformation("نتیجه تست (موفق)", fmt.Sprintf("لینک برای واحد '%s' معتبر به نظر می‌رسد و سلول‌های %s,%s,%s (قالب '%s') با موفقیت خوانده شدند (یا خطای بحرانی در خواندن آن‌ها نبود).%s", selectedDept, layout.TotalHoursCell, layout.ProductionDaysCell, layout.MonthCell, layout.Name, warningMsg), m.parentWindow)
+				if report.HasIssues() {
+					fyne.Do(func() {
+						ShowValidationReportDialog(m.parentWindow, "گزارش بررسی فایل واحد "+selectedDept, report, nil)
+					})
 				}
-				dialog.ShowInformation("نتیجه تست (موفق)", fmt.Sprintf("لینک برای واحد '%s' معتبر به نظر می‌رسد و سلول‌های F1,F2,F3 با موفقیت خوانده شدند (یا خطای بحرانی در خواندن آن‌ها نبود).%s", selectedDept, warningMsg), m.parentWindow)
 			}
 			// })
 		}()
 	}, m.parentWindow)
 }
+
+// onEditLayout قالب فایل اکسل (شیت، سلول‌ها یا نام محدوده‌ها و ردیف هدر) یک واحد را ویرایش می‌کند.
+// فیلدهای خالی مقدار پیش‌فرض دارند.
+func (m *cloudLinkManagerDialog) onEditLayout(deptShiftName string) {
+	link := m.editableLinks[deptShiftName]
+	layout := link.Layout
+	defaults := core.DefaultLayoutProfile()
+
+	newEntry := func(value, placeholder string) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.SetText(value)
+		entry.SetPlaceHolder(placeholder)
+		return entry
+	}
+	nameEntry := newEntry(layout.Name, defaults.Name)
+	sheetEntry := newEntry(layout.SheetName, "اولین شیت")
+	totalHoursEntry := newEntry(layout.TotalHoursCell, defaults.TotalHoursCell)
+	prodDaysEntry := newEntry(layout.ProductionDaysCell, defaults.ProductionDaysCell)
+	monthEntry := newEntry(layout.MonthCell, defaults.MonthCell)
+	headerRowText := ""
+	if layout.HeaderRow > 0 {
+		headerRowText = strconv.Itoa(layout.HeaderRow)
+	}
+	headerRowEntry := newEntry(headerRowText, strconv.Itoa(defaults.HeaderRow))
+	hint := widget.NewLabel("برای سلول‌ها، آدرس (مانند F1) یا نام محدوده تعریف شده در فایل اکسل را وارد کنید. فیلد خالی یعنی مقدار پیش‌فرض.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	dialog.ShowForm("قالب فایل "+deptShiftName, "تأیید", "انصراف", []*widget.FormItem{
+		widget.NewFormItem("نام قالب:", nameEntry),
+		widget.NewFormItem("نام شیت:", sheetEntry),
+		widget.NewFormItem("سلول سرانه:", totalHoursEntry),
+		widget.NewFormItem("سلول روزهای تولید:", prodDaysEntry),
+		widget.NewFormItem("سلول ماه:", monthEntry),
+		widget.NewFormItem("ردیف هدر پرسنل:", headerRowEntry),
+		widget.NewFormItem("", hint),
+	}, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		headerRow := 0
+		if text := strings.TrimSpace(headerRowEntry.Text); text != "" {
+			val, err := strconv.Atoi(text)
+			if err != nil || val < 1 {
+				dialog.ShowError(fmt.Errorf("ردیف هدر باید عدد صحیح مثبت باشد"), m.parentWindow)
+				return
+			}
+			headerRow = val
+		}
+		link.Layout = core.LayoutProfile{
+			Name:               strings.TrimSpace(nameEntry.Text),
+			SheetName:          strings.TrimSpace(sheetEntry.Text),
+			TotalHoursCell:     strings.TrimSpace(totalHoursEntry.Text),
+			ProductionDaysCell: strings.TrimSpace(prodDaysEntry.Text),
+			MonthCell:          strings.TrimSpace(monthEntry.Text),
+			HeaderRow:          headerRow,
+		}
+		m.editableLinks[deptShiftName] = link
+		m.linksTable.Refresh()
+	}, m.parentWindow)
+}
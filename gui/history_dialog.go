@@ -0,0 +1,237 @@
+package gui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"overtime_go/core"
+	"overtime_go/history"
+	"overtime_go/storage"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+var historyMonthOptions = []string{"3", "6", "12"}
+
+type historyDialog struct {
+	parentWindow fyne.Window
+	records      []core.DepartmentData
+	periods      []core.Period // دوره‌های موجود در بایگانی، به ترتیب زمانی
+
+	// سابقه پرسنل
+	shownPeriods []core.Period
+	histories    []history.EmployeeHistory
+	jumpPeriods  map[string]map[core.Period]bool
+	employeeRows *widget.Table
+	jumpsLabel   *widget.Label
+
+	// مقایسه واحدها
+	summaries       []history.DepartmentSummary
+	departmentTable *widget.Table
+}
+
+// ShowHistoryDialog سابقه ماهانه پرسنل و مقایسه واحدها را از روی بایگانی تخصیص‌های نهایی نمایش می‌دهد.
+// فقط واحدهای accessibleDepts در نظر گرفته می‌شوند.
+func ShowHistoryDialog(parent fyne.Window, accessibleDepts []string) {
+	archived, err := storage.LoadArchive()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("خطا در خواندن بایگانی تخصیص‌ها: %w", err), parent)
+		return
+	}
+	allowed := make(map[string]bool, len(accessibleDepts))
+	for _, dept := range accessibleDepts {
+		allowed[dept] = true
+	}
+
+	h := &historyDialog{parentWindow: parent}
+	seenPeriods := make(map[core.Period]bool)
+	for _, record := range archived {
+		if !allowed[record.Data.DepartmentShiftName] {
+			continue
+		}
+		h.records = append(h.records, record.Data)
+		if !seenPeriods[record.Data.Period] {
+			seenPeriods[record.Data.Period] = true
+			h.periods = append(h.periods, record.Data.Period)
+		}
+	}
+	sort.Slice(h.periods, func(i, j int) bool { return h.periods[i].Before(h.periods[j]) })
+	if len(h.records) == 0 {
+		dialog.ShowInformation("تاریخچه", "هنوز هیچ تخصیص نهایی (خروجی گرفته شده) برای واحدهای شما بایگانی نشده است.", parent)
+		return
+	}
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem("سابقه پرسنل", h.createEmployeeHistoryTab()),
+		container.NewTabItem("مقایسه واحدها", h.createDepartmentComparisonTab()),
+	)
+	d := dialog.NewCustom("تاریخچه تخصیص‌ها", "بستن", trackActivity(tabs), parent)
+	d.Resize(fyne.NewSize(900, 600))
+	d.Show()
+}
+
+func (h *historyDialog) createEmployeeHistoryTab() fyne.CanvasObject {
+	h.jumpsLabel = widget.NewLabel("")
+	h.jumpsLabel.Wrapping = fyne.TextWrapWord
+
+	h.employeeRows = widget.NewTable(
+		func() (int, int) { return len(h.histories) + 1, len(h.shownPeriods) + 3 },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		h.updateEmployeeCell,
+	)
+	h.employeeRows.SetColumnWidth(0, 100)
+	h.employeeRows.SetColumnWidth(1, 200)
+
+	monthsSelect := widget.NewSelect(historyMonthOptions, func(selected string) {
+		n, err := strconv.Atoi(selected)
+		if err != nil {
+			return
+		}
+		h.loadEmployeeHistories(n)
+	})
+	monthsSelect.SetSelected(historyMonthOptions[0])
+
+	controls := container.NewHBox(widget.NewLabel("تعداد ماه‌های اخیر:"), monthsSelect)
+	return container.NewBorder(controls, h.jumpsLabel, nil, nil, h.employeeRows)
+}
+
+func (h *historyDialog) loadEmployeeHistories(months int) {
+	h.shownPeriods = history.RecentPeriods(h.periods[len(h.periods)-1], months)
+	h.histories = history.BuildEmployeeHistories(h.records, h.shownPeriods)
+
+	jumps := history.DetectJumps(h.histories, h.shownPeriods, history.DefaultJumpThreshold)
+	h.jumpPeriods = make(map[string]map[core.Period]bool)
+	for _, j := range jumps {
+		if h.jumpPeriods[j.EmployeeID] == nil {
+			h.jumpPeriods[j.EmployeeID] = make(map[core.Period]bool)
+		}
+		h.jumpPeriods[j.EmployeeID][j.To] = true
+	}
+	if len(jumps) == 0 {
+		h.jumpsLabel.SetText("افزایش ناگهانی ساعات در این بازه مشاهده نشد.")
+	} else {
+		h.jumpsLabel.SetText(fmt.Sprintf("%d مورد افزایش ناگهانی (بیش از %d ساعت و %.0f درصد نسبت به ماه قبل) با علامت ▲ مشخص شده است.",
+			len(jumps), history.DefaultJumpThreshold.MinIncreaseHours, history.DefaultJumpThreshold.MinIncreasePercent))
+	}
+
+	for col := range h.shownPeriods {
+		h.employeeRows.SetColumnWidth(col+2, 110)
+	}
+	h.employeeRows.SetColumnWidth(len(h.shownPeriods)+2, 70)
+	h.employeeRows.Refresh()
+}
+
+func (h *historyDialog) updateEmployeeCell(id widget.TableCellID, template fyne.CanvasObject) {
+	label := template.(*widget.Label)
+	periodCols := len(h.shownPeriods)
+	if id.Row == 0 {
+		switch {
+		case id.Col == 0:
+			label.SetText("کد پرسنلی")
+		case id.Col == 1:
+			label.SetText("نام پرسنل")
+		case id.Col-2 < periodCols:
+			label.SetText(h.shownPeriods[id.Col-2].String())
+		default:
+			label.SetText("وضعیت")
+		}
+		label.TextStyle = fyne.TextStyle{Bold: true}
+		label.Refresh()
+		return
+	}
+
+	label.TextStyle = fyne.TextStyle{}
+	if id.Row-1 >= len(h.histories) {
+		label.SetText("")
+		return
+	}
+	emp := h.histories[id.Row-1]
+	switch {
+	case id.Col == 0:
+		label.SetText(emp.ID)
+	case id.Col == 1:
+		label.SetText(emp.Name)
+	case id.Col-2 < periodCols:
+		period := h.shownPeriods[id.Col-2]
+		hours, ok := emp.HoursIn(period)
+		text := "-"
+		if ok {
+			text = strconv.Itoa(hours)
+			if h.jumpPeriods[emp.ID][period] {
+				text += " ▲"
+			}
+		}
+		label.SetText(text)
+	default:
+		if len(h.jumpPeriods[emp.ID]) > 0 {
+			label.SetText("بررسی")
+		} else {
+			label.SetText("")
+		}
+	}
+}
+
+func (h *historyDialog) createDepartmentComparisonTab() fyne.CanvasObject {
+	h.departmentTable = widget.NewTable(
+		func() (int, int) { return len(h.summaries) + 1, 6 },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		h.updateDepartmentCell,
+	)
+	h.departmentTable.SetColumnWidth(0, 260)
+	for col := 1; col < 6; col++ {
+		h.departmentTable.SetColumnWidth(col, 110)
+	}
+
+	periodNames := make([]string, len(h.periods))
+	for i, p := range h.periods {
+		periodNames[len(h.periods)-1-i] = p.String() // جدیدترین دوره اول
+	}
+	periodSelect := widget.NewSelect(periodNames, func(selected string) {
+		for _, p := range h.periods {
+			if p.String() == selected {
+				h.summaries = history.CompareDepartments(h.records, p)
+				h.departmentTable.Refresh()
+				return
+			}
+		}
+	})
+	periodSelect.SetSelected(periodNames[0])
+
+	controls := container.NewHBox(widget.NewLabel("دوره:"), periodSelect)
+	return container.NewBorder(controls, nil, nil, nil, h.departmentTable)
+}
+
+func (h *historyDialog) updateDepartmentCell(id widget.TableCellID, template fyne.CanvasObject) {
+	label := template.(*widget.Label)
+	if id.Row == 0 {
+		headers := []string{"واحد", "تعداد پرسنل", "سرانه", "تخصیص یافته", "میانگین هر نفر", "بیشترین"}
+		label.SetText(headers[id.Col])
+		label.TextStyle = fyne.TextStyle{Bold: true}
+		label.Refresh()
+		return
+	}
+	label.TextStyle = fyne.TextStyle{}
+	if id.Row-1 >= len(h.summaries) {
+		label.SetText("")
+		return
+	}
+	s := h.summaries[id.Row-1]
+	switch id.Col {
+	case 0:
+		label.SetText(s.DepartmentShift)
+	case 1:
+		label.SetText(strconv.Itoa(s.EmployeeCount))
+	case 2:
+		label.SetText(strconv.Itoa(s.Budget))
+	case 3:
+		label.SetText(strconv.Itoa(s.Allocated))
+	case 4:
+		label.SetText(fmt.Sprintf("%.1f", s.AverageHours()))
+	case 5:
+		label.SetText(strconv.Itoa(s.MaxHours))
+	}
+}
@@ -0,0 +1,182 @@
+package gui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// idleWarningBefore فاصله زمانی نمایش هشدار پیش از پایان نشست است.
+const idleWarningBefore = time.Minute
+
+// activeIdleMonitor پایشگر عدم فعالیت نشست جاری است و با EndSession متوقف می‌شود.
+var activeIdleMonitor *idleMonitor
+
+// activeMainUI صفحه اصلی نشست جاری است تا EndSession ویرایش در حال تایپ آن را پیش از خروج ثبت کند.
+var activeMainUI *MainUI
+
+// idleMonitor زمان آخرین فعالیت کاربر را نگه می‌دارد و پس از گذشت timeout بدون فعالیت،
+// ابتدا هشدار می‌دهد و سپس onExpire را (در رشته اصلی رابط کاربری) فراخوانی می‌کند.
+type idleMonitor struct {
+	window   fyne.Window
+	timeout  time.Duration
+	onExpire func()
+
+	mu           sync.Mutex
+	lastActivity time.Time
+	stopOnce     sync.Once
+	stop         chan struct{}
+
+	// فقط در رشته اصلی رابط کاربری استفاده می‌شوند
+	warningDialog dialog.Dialog
+	warningLabel  *widget.Label
+}
+
+func newIdleMonitor(win fyne.Window, timeout time.Duration, onExpire func()) *idleMonitor {
+	return &idleMonitor{
+		window:       win,
+		timeout:      timeout,
+		onExpire:     onExpire,
+		lastActivity: time.Now(),
+		stop:         make(chan struct{}),
+	}
+}
+
+// Touch یک فعالیت کاربر را ثبت می‌کند.
+func (m *idleMonitor) Touch() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.lastActivity = time.Now()
+	m.mu.Unlock()
+}
+
+func (m *idleMonitor) idleFor() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return time.Since(m.lastActivity)
+}
+
+// Start بررسی دوره‌ای عدم فعالیت را آغاز می‌کند.
+func (m *idleMonitor) Start() {
+	ticker := time.NewTicker(time.Second)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				remaining := m.timeout - m.idleFor()
+				if remaining <= 0 {
+					m.Stop()
+					fyne.Do(func() {
+						m.hideWarning()
+						m.onExpire()
+					})
+					return
+				}
+				fyne.Do(func() { m.updateWarning(remaining) })
+			}
+		}
+	}()
+}
+
+// Stop پایشگر را متوقف می‌کند؛ فراخوانی چندباره بی‌اثر است.
+func (m *idleMonitor) Stop() {
+	if m == nil {
+		return
+	}
+	m.stopOnce.Do(func() { close(m.stop) })
+	fyne.Do(m.hideWarning)
+}
+
+func (m *idleMonitor) updateWarning(remaining time.Duration) {
+	if remaining > idleWarningBefore {
+		m.hideWarning()
+		return
+	}
+	text := fmt.Sprintf("به دلیل عدم فعالیت، نشست شما تا %d ثانیه دیگر بسته می‌شود.\nتغییرات ذخیره خواهند شد.", int(remaining.Round(time.Second)/time.Second))
+	if m.warningDialog != nil {
+		m.warningLabel.SetText(text)
+		return
+	}
+	m.warningLabel = widget.NewLabel(text)
+	m.warningDialog = dialog.NewCustom("پایان نشست", "ادامه کار", m.warningLabel, m.window)
+	m.warningDialog.SetOnClosed(func() {
+		m.warningDialog = nil
+		m.Touch()
+	})
+	m.warningDialog.Show()
+}
+
+func (m *idleMonitor) hideWarning() {
+	if m.warningDialog == nil {
+		return
+	}
+	d := m.warningDialog
+	m.warningDialog = nil
+	d.Hide()
+}
+
+// EndSession ویرایش در حال تایپ را ثبت و پایشگر عدم فعالیت نشست جاری را متوقف می‌کند و باید هنگام خروج از حساب
+// فراخوانی شود.
+func EndSession() {
+	if activeMainUI != nil {
+		activeMainUI.commitPendingEdit()
+		activeMainUI = nil
+	}
+	activeIdleMonitor.Stop()
+	activeIdleMonitor = nil
+}
+
+// touchActivity یک فعالیت کاربر را در پایشگر نشست جاری ثبت می‌کند و برای callbackهای ورودی (سرانه، روش توزیع،
+// حداقل و سقف و فیلترهای دیالوگ‌ها) استفاده می‌شود؛ Fyne تایپ در ورودی دارای فوکوس را به Canvas نمی‌فرستد.
+func touchActivity() {
+	activeIdleMonitor.Touch()
+}
+
+// trackActivity محتوای یک دیالوگ را در activityTracker قرار می‌دهد تا حرکت ماوس روی آن هم فعالیت حساب شود.
+func trackActivity(content fyne.CanvasObject) fyne.CanvasObject {
+	return newActivityTracker(content, touchActivity)
+}
+
+// activityTracker محتوای پنجره یا دیالوگ را در بر می‌گیرد و حرکت ماوس روی آن را به عنوان فعالیت کاربر ثبت می‌کند.
+// رویدادهای Hoverable فقط به عمیق‌ترین Hoverable زیر نشانگر (جدول، ورودی، دکمه و ...) می‌رسند، اما درایور
+// دسکتاپ هنگام هر حرکت ماوس Cursor همه اشیای زیر نشانگر را می‌پرسد؛ بنابراین حرکت روی هر ویجت داخلی هم دیده می‌شود.
+type activityTracker struct {
+	widget.BaseWidget
+	content    fyne.CanvasObject
+	onActivity func()
+}
+
+var (
+	_ desktop.Hoverable  = (*activityTracker)(nil)
+	_ desktop.Cursorable = (*activityTracker)(nil)
+)
+
+func newActivityTracker(content fyne.CanvasObject, onActivity func()) *activityTracker {
+	t := &activityTracker{content: content, onActivity: onActivity}
+	t.ExtendBaseWidget(t)
+	return t
+}
+
+func (t *activityTracker) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(t.content)
+}
+
+// Cursor نشانگر پیش‌فرض را برمی‌گرداند؛ نشانگر ویجت‌های داخلی (مانند ورودی متن) بر آن اولویت دارد.
+func (t *activityTracker) Cursor() desktop.Cursor {
+	t.onActivity()
+	return desktop.DefaultCursor
+}
+
+func (t *activityTracker) MouseIn(*desktop.MouseEvent)    { t.onActivity() }
+func (t *activityTracker) MouseMoved(*desktop.MouseEvent) { t.onActivity() }
+func (t *activityTracker) MouseOut()                      {}
@@ -23,40 +23,72 @@ func CreateLoginUI(win fyne.Window, app fyne.App, settings config.AppSettings, o
 
 	passwordEntry := widget.NewPasswordEntry()
 	passwordEntry.SetPlaceHolder("رمز عبور")
-	if settings.RememberMe && settings.Password != "" {
-		passwordEntry.SetText(settings.Password)
+	// با توکن نشست معتبر، ورود بدون وارد کردن رمز برای همان نام کاربری ممکن است
+	if settings.RememberMe && settings.SessionToken != "" {
+		passwordEntry.SetPlaceHolder("ورود با نشست ذخیره شده (یا رمز عبور)")
 	}
 
 	rememberCheck := widget.NewCheck("مرا به خاطر بسپار", nil)
 	rememberCheck.SetChecked(settings.RememberMe)
 
+	completeLogin := func(user *core.User) {
+		settings.Username = ""
+		settings.SessionToken = ""
+		settings.RememberMe = false
+		if rememberCheck.Checked {
+			token, err := auth.IssueSessionToken(user.Username)
+			if err != nil {
+				fmt.Printf("هشدار: صدور توکن نشست برای '%s' انجام نشد: %v\n", user.Username, err)
+			} else {
+				settings.Username = user.Username
+				settings.SessionToken = token
+				settings.RememberMe = true
+			}
+		}
+		config.SaveSettings(app, settings)
+
+		// dialog.ShowInformation("موفقیت", "ورود موفقیت آمیز بود!", win) // این دیالوگ را حذف می‌کنیم تا بلافاصله به پنجره اصلی برود
+		// win.Hide() // این کار در main.go انجام می‌شود
+		onLoginSuccess(*user)
+	}
+
 	loginButton := widget.NewButton("ورود", func() {
 		username := usernameEntry.Text
 		password := passwordEntry.Text
 
+		if username != "" && password == "" && settings.SessionToken != "" && username == settings.Username {
+			user, err := auth.AuthenticateSessionToken(settings.SessionToken)
+			if err != nil || user.Username != username {
+				settings.SessionToken = ""
+				config.SaveSettings(app, settings)
+				passwordEntry.SetPlaceHolder("رمز عبور")
+				dialog.ShowError(auth.ErrInvalidSessionToken, win)
+				return
+			}
+			completeLogin(user)
+			return
+		}
+
 		if username == "" || password == "" {
 			dialog.ShowInformation("خطا", "نام کاربری و رمز عبور را وارد کنید.", win)
 			return
 		}
 
-		user, authenticated := auth.AuthenticateUser(username, password)
-		if authenticated {
-			if rememberCheck.Checked {
-				settings.Username = username
-				settings.Password = password
-				settings.RememberMe = true
-			} else {
-				settings.Username = ""
-				settings.Password = ""
-				settings.RememberMe = false
+		user, err := auth.AuthenticateUser(username, password)
+		if err == nil {
+			if user.MustChangePassword {
+				// تا تغییر رمز انجام نشود، ورود کامل نمی‌شود
+				ShowChangePasswordDialog(win, username, true, func(string) {
+					user.MustChangePassword = false
+					completeLogin(user)
+				}, func() {
+					passwordEntry.SetText("")
+				})
+				return
 			}
-			config.SaveSettings(app, settings)
-
-			// dialog.ShowInformation("موفقیت", "ورود موفقیت آمیز بود!", win) // این دیالوگ را حذف می‌کنیم تا بلافاصله به پنجره اصلی برود
-			// win.Hide() // این کار در main.go انجام می‌شود
-			onLoginSuccess(*user)
+			completeLogin(user)
 		} else {
-			dialog.ShowError(fmt.Errorf("نام کاربری یا رمز عبور اشتباه است."), win)
+			dialog.ShowError(err, win)
 			passwordEntry.SetText("")
 		}
 	})
@@ -18,10 +18,15 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"overtime_go/allocation"
+	"overtime_go/audit"
+	"overtime_go/auth"
 	"overtime_go/cloud"
+	"overtime_go/config"
 	"overtime_go/core"
 	"overtime_go/excel"
 	"overtime_go/resources"
+	"overtime_go/storage"
 )
 
 type MainUI struct {
@@ -33,10 +38,16 @@ type MainUI struct {
 	currentEmployees      binding.UntypedList
 
 	deptComboBox        *widget.Select
+	deptShiftByLabel    map[string]string // نام نمایشی ← کلید واحد-شیفت در فهرست واحدها
 	totalHoursInput     *widget.Entry
 	productionDaysInput *widget.Entry
+	strategySelect      *widget.Select
+	fillCapInput        *widget.Entry
+	defaultMinInput     *widget.Entry // فقط برای کاربران دارای مجوز ویرایش سرانه ساخته می‌شود
+	defaultMaxInput     *widget.Entry
 	employeesTable      *widget.Table
 	summaryLabel        *widget.Label
+	lastSavedLabel      *widget.Label
 	exportButton        *widget.Button
 	updateCloudButton   *widget.Button
 	manageLinksButton   *widget.Button
@@ -46,6 +57,16 @@ type MainUI struct {
 	adminCreateTableButton    *widget.Button
 	adminImportExcelButton    *widget.Button
 
+	lastDiagnostics []allocation.Diagnostic
+	lastSavedAt     map[string]time.Time
+	committedState  map[string]core.DepartmentData // آخرین نسخه ثبت شده هر واحد؛ مبنای گزارش حسابرسی و پشته بازگشت
+	undoStacks      map[string]*core.UndoStack
+	undoButton      *widget.Button
+	redoButton      *widget.Button
+	pendingEdit     *typedEdit // ویرایش در حال تایپ که هنوز در گزارش حسابرسی و پشته بازگشت ثبت نشده است
+	typing          bool       // در حین ذخیره تغییر ورودی در حال تایپ true است
+
+	idle          *idleMonitor
 	logoutHandler func()
 }
 
@@ -56,7 +77,12 @@ func CreateMainUI(win fyne.Window, appInst fyne.App, user *core.User, logoutCall
 		User:             user,
 		currentEmployees: binding.NewUntypedList(),
 		logoutHandler:    logoutCallback,
+		lastSavedAt:      make(map[string]time.Time),
+		committedState:   make(map[string]core.DepartmentData),
+		undoStacks:       make(map[string]*core.UndoStack),
 	}
+	ui.restoreSavedDepartments()
+	activeMainUI = ui
 
 	topControls := ui.createTopControls()
 
@@ -75,9 +101,11 @@ func CreateMainUI(win fyne.Window, appInst fyne.App, user *core.User, logoutCall
 
 	ui.summaryLabel = widget.NewLabel("مجموع ساعات: 0 / سرانه: 0")
 	ui.summaryLabel.Alignment = fyne.TextAlignCenter
+	ui.lastSavedLabel = widget.NewLabel("")
+	ui.lastSavedLabel.Alignment = fyne.TextAlignCenter
 
 	tableGroupCard := widget.NewCard("تخصیص ساعات اضافه کاری پرسنل", "",
-		container.NewBorder(nil, ui.summaryLabel, nil, nil, tableContainer),
+		container.NewBorder(nil, container.NewVBox(ui.summaryLabel, ui.lastSavedLabel), nil, nil, tableContainer),
 	)
 
 	bottomButtons := ui.createBottomButtons()
@@ -93,7 +121,7 @@ func CreateMainUI(win fyne.Window, appInst fyne.App, user *core.User, logoutCall
 	accessibleDepts := ui.getAccessibleDepartmentShifts()
 	if len(accessibleDepts) > 0 && ui.deptComboBox != nil {
 		ui.loadDepartmentDataByName(accessibleDepts[0])
-		ui.deptComboBox.Selected = accessibleDepts[0]
+		ui.deptComboBox.Selected = core.DepartmentShiftDisplayName(accessibleDepts[0])
 		ui.refreshUIForCurrentDepartment()
 	} else if ui.deptComboBox != nil {
 		ui.deptComboBox.SetSelected("")
@@ -101,13 +129,88 @@ func CreateMainUI(win fyne.Window, appInst fyne.App, user *core.User, logoutCall
 	}
 	ui.updateSummaryLabel()
 
-	return mainContent
+	ui.setupUndoShortcuts()
+	ui.startIdleMonitor()
+	return trackActivity(mainContent)
 }
 
+// startIdleMonitor خروج خودکار پس از مدت عدم فعالیت تنظیم شده (idle_timeout_minutes) را فعال می‌کند.
+// با تغییر مدت در "تنظیمات نشست" دوباره فراخوانی می‌شود و پایشگر قبلی را متوقف می‌کند.
+func (ui *MainUI) startIdleMonitor() {
+	activeIdleMonitor.Stop()
+	activeIdleMonitor = nil
+	ui.idle = nil
+	minutes := config.LoadSettings(ui.App).IdleTimeoutMinutes
+	if minutes <= 0 {
+		return
+	}
+	ui.idle = newIdleMonitor(ui.Window, time.Duration(minutes)*time.Minute, ui.onIdleTimeout)
+	activeIdleMonitor = ui.idle
+	ui.Window.Canvas().SetOnTypedKey(func(*fyne.KeyEvent) { ui.idle.Touch() })
+	ui.Window.Canvas().SetOnTypedRune(func(rune) { ui.idle.Touch() })
+	ui.idle.Start()
+}
+
+// onSessionSettings مدت عدم فعالیت تا خروج خودکار را (برای همه کاربران این نصب برنامه) تنظیم می‌کند.
+func (ui *MainUI) onSessionSettings() {
+	if !ui.authorize(auth.CapManageUsers, "") {
+		return
+	}
+	settings := config.LoadSettings(ui.App)
+	minutesEntry := widget.NewEntry()
+	minutesEntry.SetText(strconv.Itoa(settings.IdleTimeoutMinutes))
+	minutesEntry.Validator = func(s string) error {
+		val, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || val < 0 {
+			return fmt.Errorf("باید عدد صحیح غیرمنفی باشد")
+		}
+		return nil
+	}
+	dialog.ShowForm("تنظیمات نشست", "ذخیره", "انصراف", []*widget.FormItem{
+		widget.NewFormItem("خروج خودکار پس از (دقیقه):", minutesEntry),
+		widget.NewFormItem("", widget.NewLabel("صفر یعنی خروج خودکار غیرفعال است.")),
+	}, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		minutes, _ := strconv.Atoi(strings.TrimSpace(minutesEntry.Text))
+		settings := config.LoadSettings(ui.App)
+		settings.IdleTimeoutMinutes = minutes
+		config.SaveSettings(ui.App, settings)
+		ui.startIdleMonitor()
+	}, ui.Window)
+}
+
+// onIdleTimeout داده‌های واحد جاری را ذخیره، توکن‌های "مرا به خاطر بسپار" را باطل و از حساب خارج می‌کند.
+func (ui *MainUI) onIdleTimeout() {
+	fmt.Printf("نشست کاربر '%s' به دلیل عدم فعالیت بسته شد.\n", ui.User.Username)
+	if auth.Can(ui.User, auth.CapEditAllocation) {
+		ui.saveDepartment(ui.currentDepartmentData, audit.SourceTableEdit)
+	}
+	if err := auth.RevokeSessions(ui.User.Username); err != nil {
+		fmt.Printf("هشدار: ابطال نشست‌های کاربر '%s' انجام نشد: %v\n", ui.User.Username, err)
+	}
+	if ui.logoutHandler != nil {
+		ui.logoutHandler()
+	}
+}
 func (ui *MainUI) refreshUIForCurrentDepartment() {
 	if ui.currentDepartmentData != nil {
 		ui.totalHoursInput.SetText(strconv.Itoa(ui.currentDepartmentData.TotalHours))
 		ui.productionDaysInput.SetText(strconv.Itoa(ui.currentDepartmentData.ProductionDays))
+		ui.strategySelect.SetSelected(effectiveStrategy(ui.currentDepartmentData).DisplayName())
+		ui.fillCapInput.SetText(strconv.Itoa(ui.currentDepartmentData.FillCapHours))
+		if auth.Can(ui.User, auth.CapEditAllocation) {
+			ui.strategySelect.Enable()
+		} else {
+			ui.strategySelect.Disable()
+		}
+		ui.updateFillCapInputState()
+		if ui.defaultMinInput != nil {
+			ui.defaultMinInput.SetText(strconv.Itoa(ui.currentDepartmentData.DefaultMinHours))
+			ui.defaultMaxInput.SetText(strconv.Itoa(ui.currentDepartmentData.DefaultMaxHours))
+		}
+		ui.updateDefaultLimitInputsState()
 
 		var empInterfaces []interface{}
 		for i := range ui.currentDepartmentData.Employees {
@@ -115,10 +218,10 @@ func (ui *MainUI) refreshUIForCurrentDepartment() {
 		}
 		ui.currentEmployees.Set(empInterfaces)
 
-		if ui.User.Role != "admin" {
-			ui.totalHoursInput.Disable()
-		} else {
+		if auth.Can(ui.User, auth.CapEditBudget) {
 			ui.totalHoursInput.Enable()
+		} else {
+			ui.totalHoursInput.Disable()
 		}
 
 		enableActions := len(ui.currentDepartmentData.Employees) > 0
@@ -127,7 +230,9 @@ func (ui *MainUI) refreshUIForCurrentDepartment() {
 		} else {
 			ui.exportButton.Disable()
 		}
-		ui.resetButton.Enable()
+		if auth.Can(ui.User, auth.CapEditAllocation) {
+			ui.resetButton.Enable()
+		}
 
 		if ui.updateCloudButton != nil {
 			if ui.currentDepartmentData != nil || (ui.deptComboBox != nil && ui.deptComboBox.Selected != "" && ui.deptComboBox.Selected != ui.deptComboBox.PlaceHolder) {
@@ -137,7 +242,7 @@ func (ui *MainUI) refreshUIForCurrentDepartment() {
 			}
 		}
 
-		if ui.User.Role == "admin" && ui.adminManualEmployeesInput != nil {
+		if ui.adminManualEmployeesInput != nil {
 			enableAdminControls := ui.currentDepartmentData != nil || (ui.deptComboBox != nil && ui.deptComboBox.Selected != "" && ui.deptComboBox.Selected != ui.deptComboBox.PlaceHolder)
 			if enableAdminControls {
 				ui.adminManualEmployeesInput.Enable()
@@ -154,13 +259,138 @@ func (ui *MainUI) refreshUIForCurrentDepartment() {
 	}
 	ui.employeesTable.Refresh()
 	ui.updateSummaryLabel()
+	ui.updateLastSavedLabel()
+	ui.updateUndoRedoState()
+}
+
+// restoreSavedDepartments آخرین داده‌های ذخیره شده واحدها را از حافظه محلی در AllDepartmentsData بارگذاری می‌کند.
+func (ui *MainUI) restoreSavedDepartments() {
+	saved, err := storage.LoadLatestDepartments()
+	if err != nil {
+		fyne.LogError("Failed to load saved department data", err)
+	}
+	for deptShift, record := range saved {
+		core.AllDepartmentsData[deptShift] = record.Data
+		ui.lastSavedAt[deptShift] = record.SavedAt
+		ui.committedState[deptShift] = record.Data.Clone()
+	}
+	if len(saved) > 0 {
+		fmt.Printf("داده‌های ذخیره شده %d واحد بازیابی شد.\n", len(saved))
+	}
+}
+
+// saveDepartment تغییرات داده‌های یک واحد را با منبع source در گزارش حسابرسی ثبت و داده‌ها را
+// به صورت خودکار در حافظه محلی ذخیره می‌کند.
+func (ui *MainUI) saveDepartment(data *core.DepartmentData, source audit.Source) {
+	if data == nil || data.DepartmentShiftName == "" {
+		return
+	}
+	if !ui.typing {
+		ui.commitPendingEdit()
+		ui.recordChange(data, source)
+	}
+	savedAt, err := storage.SaveDepartmentData(data, ui.User.Username)
+	if err != nil {
+		fyne.LogError("Failed to auto-save department data", err)
+		if data == ui.currentDepartmentData {
+			ui.lastSavedLabel.SetText("خطا در ذخیره خودکار: " + err.Error())
+		}
+		return
+	}
+	ui.lastSavedAt[data.DepartmentShiftName] = savedAt
+	if data == ui.currentDepartmentData {
+		ui.updateLastSavedLabel()
+	}
+}
+
+// recordChange تفاوت داده‌های واحد با آخرین نسخه ثبت شده را در گزارش حسابرسی اضافه می‌کند و
+// نسخه قبلی را (به جز برای خود بازگشت و انجام مجدد) در پشته بازگشت واحد نگه می‌دارد.
+func (ui *MainUI) recordChange(data *core.DepartmentData, source audit.Source) {
+	before, ok := ui.committedState[data.DepartmentShiftName]
+	if !ok {
+		before = core.DepartmentData{DepartmentShiftName: data.DepartmentShiftName}
+	}
+	changes := audit.DiffDepartment(before, *data)
+	if len(changes) == 0 {
+		return
+	}
+	if err := audit.Record(ui.User.Username, source, data.DepartmentShiftName, changes); err != nil {
+		fyne.LogError("Failed to write audit log", err)
+	}
+	if source != audit.SourceUndo && source != audit.SourceRedo {
+		ui.undoStackFor(data.DepartmentShiftName).Push(before)
+	}
+	ui.committedState[data.DepartmentShiftName] = data.Clone()
+	ui.updateUndoRedoState()
+}
+
+// typedEdit ویرایش در حال تایپ یک ورودی (مانند ساعت یک نفر یا سرانه) است. هر کلید فقط داده را ذخیره می‌کند و
+// ثبت تغییر در گزارش حسابرسی و پشته بازگشت تا پایان ویرایش (Enter، ویرایش ورودی دیگر یا هر تغییر دیگر) به تعویق می‌افتد.
+type typedEdit struct {
+	deptShift string
+	key       string
+	source    audit.Source
+}
+
+// beginTypedEdit باید پیش از اعمال تغییر ورودی key روی داده واحد جاری فراخوانی شود. ویرایش در حال انجام ورودی
+// دیگری ابتدا ثبت می‌شود؛ تایپ پشت سر هم در همان ورودی یک تغییر واحد است.
+func (ui *MainUI) beginTypedEdit(key string, source audit.Source) {
+	if ui.currentDepartmentData == nil {
+		return
+	}
+	deptShift := ui.currentDepartmentData.DepartmentShiftName
+	if p := ui.pendingEdit; p != nil && p.deptShift == deptShift && p.key == key {
+		return
+	}
+	ui.commitPendingEdit()
+	ui.pendingEdit = &typedEdit{deptShift: deptShift, key: key, source: source}
+}
+
+// reallocateTyped سرانه را پس از تغییر ورودی در حال تایپ دوباره توزیع و ذخیره می‌کند، بدون ثبت در گزارش حسابرسی و پشته بازگشت.
+func (ui *MainUI) reallocateTyped() {
+	if ui.pendingEdit == nil {
+		return
+	}
+	ui.typing = true
+	defer func() { ui.typing = false }()
+	ui.reallocateHours(ui.pendingEdit.source)
 }
 
+// commitPendingEdit ویرایش در حال تایپ را (در صورت وجود) به عنوان یک تغییر در گزارش حسابرسی و پشته بازگشت ثبت می‌کند.
+// پیش از هر تغییر دیگر داده واحد، بازگشت، تغییر واحد و خروج از حساب فراخوانی می‌شود.
+func (ui *MainUI) commitPendingEdit() {
+	p := ui.pendingEdit
+	if p == nil {
+		return
+	}
+	ui.pendingEdit = nil
+	if data, ok := core.AllDepartmentsData[p.deptShift]; ok && data != nil {
+		ui.recordChange(data, p.source)
+	}
+}
+func (ui *MainUI) updateLastSavedLabel() {
+	if ui.currentDepartmentData == nil {
+		ui.lastSavedLabel.SetText("")
+		return
+	}
+	savedAt, ok := ui.lastSavedAt[ui.currentDepartmentData.DepartmentShiftName]
+	if !ok {
+		ui.lastSavedLabel.SetText("آخرین ذخیره: هنوز ذخیره نشده است")
+		return
+	}
+	ui.lastSavedLabel.SetText("آخرین ذخیره: " + core.FormatJalaliDateTime(savedAt))
+}
 func (ui *MainUI) createTopControls() fyne.CanvasObject {
 	deptLabel := widget.NewLabel("واحد سازمانی:")
 	accessibleDepts := ui.getAccessibleDepartmentShifts()
-	ui.deptComboBox = widget.NewSelect(accessibleDepts, func(selected string) {
-		ui.onDepartmentChanged(selected)
+	ui.deptShiftByLabel = make(map[string]string, len(accessibleDepts))
+	deptLabels := make([]string, len(accessibleDepts))
+	for i, deptShift := range accessibleDepts {
+		deptLabels[i] = core.DepartmentShiftDisplayName(deptShift)
+		ui.deptShiftByLabel[deptLabels[i]] = deptShift
+	}
+	ui.deptComboBox = widget.NewSelect(deptLabels, func(selected string) {
+		ui.onDepartmentChanged(ui.departmentShiftForLabel(selected))
 	})
 	if len(accessibleDepts) == 0 {
 		ui.deptComboBox.PlaceHolder = "-- هیچ واحدی قابل دسترسی نیست --"
@@ -175,10 +405,11 @@ func (ui *MainUI) createTopControls() fyne.CanvasObject {
 	ui.totalHoursInput.MultiLine = false
 	ui.totalHoursInput.Wrapping = fyne.TextTruncate
 
-	if ui.User.Role != "admin" {
+	if !auth.Can(ui.User, auth.CapEditBudget) {
 		ui.totalHoursInput.Disable()
 	} else {
 		ui.totalHoursInput.OnChanged = func(s string) {
+			ui.idle.Touch()
 			if ui.currentDepartmentData == nil && s != "0" && s != "" {
 				ui.totalHoursInput.SetText("0")
 				dialog.ShowInformation("راهنما", "لطفا ابتدا یک واحد را انتخاب کنید تا سرانه آن را تغییر دهید.", ui.Window)
@@ -191,36 +422,79 @@ func (ui *MainUI) createTopControls() fyne.CanvasObject {
 			originalText := strconv.Itoa(ui.currentDepartmentData.TotalHours)
 			if err == nil && newTotal >= 0 {
 				if ui.currentDepartmentData.TotalHours != newTotal {
+					ui.beginTypedEdit("budget", audit.SourceBudgetEdit)
 					ui.currentDepartmentData.TotalHours = newTotal
-					ui.reallocateHours()
+					ui.reallocateTyped()
 				}
 			} else if s != "" {
 				ui.totalHoursInput.SetText(originalText)
 				dialog.ShowError(fmt.Errorf("مقدار سرانه باید عدد صحیح غیرمنفی باشد"), ui.Window)
 			} else if s == "" {
 				if ui.currentDepartmentData.TotalHours != 0 {
+					ui.beginTypedEdit("budget", audit.SourceBudgetEdit)
 					ui.currentDepartmentData.TotalHours = 0
-					ui.reallocateHours()
+					ui.reallocateTyped()
 				}
 			}
 		}
+		ui.totalHoursInput.OnSubmitted = func(string) { ui.commitPendingEdit() }
 	}
 	prodDaysLabel := widget.NewLabel("روزهای تولید:")
 	ui.productionDaysInput = widget.NewEntry()
 	ui.productionDaysInput.SetText("0")
 	// ui.productionDaysInput.Alignment حذف شد
 	ui.productionDaysInput.Disable()
-	baseControls := container.New(layout.NewFormLayout(),
+
+	strategyLabel := widget.NewLabel("روش توزیع:")
+	strategyNames := make([]string, 0, len(core.AllocationStrategies))
+	for _, strategy := range core.AllocationStrategies {
+		strategyNames = append(strategyNames, strategy.DisplayName())
+	}
+	ui.strategySelect = widget.NewSelect(strategyNames, ui.onStrategyChanged)
+	ui.strategySelect.Selected = core.StrategyEqual.DisplayName()
+	ui.strategySelect.Disable()
+
+	fillCapLabel := widget.NewLabel("سقف هر نفر (ساعت):")
+	ui.fillCapInput = widget.NewEntry()
+	ui.fillCapInput.SetText("0")
+	ui.fillCapInput.OnChanged = ui.onFillCapChanged
+	ui.fillCapInput.OnSubmitted = func(string) { ui.commitPendingEdit() }
+	ui.fillCapInput.Disable()
+
+	baseFormItems := []fyne.CanvasObject{
 		deptLabel, ui.deptComboBox,
 		seranehLabel, ui.totalHoursInput,
 		prodDaysLabel, ui.productionDaysInput,
-	)
-	if ui.User.Role == "admin" {
+		strategyLabel, ui.strategySelect,
+		fillCapLabel, ui.fillCapInput,
+	}
+	if auth.Can(ui.User, auth.CapEditBudget) {
+		defaultMinLabel := widget.NewLabel("حداقل پیش‌فرض هر نفر:")
+		ui.defaultMinInput = widget.NewEntry()
+		ui.defaultMinInput.SetText("0")
+		ui.defaultMinInput.OnChanged = func(s string) {
+			ui.onDepartmentLimitChanged(ui.defaultMinInput, "default-min", s, func(d *core.DepartmentData) *int { return &d.DefaultMinHours })
+		}
+		ui.defaultMinInput.OnSubmitted = func(string) { ui.commitPendingEdit() }
+		ui.defaultMinInput.Disable()
+		defaultMaxLabel := widget.NewLabel("سقف پیش‌فرض هر نفر (0 = بدون سقف):")
+		ui.defaultMaxInput = widget.NewEntry()
+		ui.defaultMaxInput.SetText("0")
+		ui.defaultMaxInput.OnChanged = func(s string) {
+			ui.onDepartmentLimitChanged(ui.defaultMaxInput, "default-max", s, func(d *core.DepartmentData) *int { return &d.DefaultMaxHours })
+		}
+		ui.defaultMaxInput.OnSubmitted = func(string) { ui.commitPendingEdit() }
+		ui.defaultMaxInput.Disable()
+		baseFormItems = append(baseFormItems, defaultMinLabel, ui.defaultMinInput, defaultMaxLabel, ui.defaultMaxInput)
+	}
+	baseControls := container.New(layout.NewFormLayout(), baseFormItems...)
+	if auth.Can(ui.User, auth.CapImport) {
 		adminTitle := widget.NewLabelWithStyle("کنترل‌های ادمین:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
 
 		adminEmpCountLabel := widget.NewLabel("تعداد پرسنل (جدول دستی):")
 		ui.adminManualEmployeesInput = widget.NewEntry()
 		ui.adminManualEmployeesInput.SetPlaceHolder("مثلا: 5")
+		ui.adminManualEmployeesInput.OnChanged = func(string) { ui.idle.Touch() }
 		ui.adminManualEmployeesInput.Validator = func(s string) error {
 			if s == "" {
 				return nil
@@ -262,19 +536,49 @@ func (ui *MainUI) createTopControls() fyne.CanvasObject {
 func (ui *MainUI) createBottomButtons() fyne.CanvasObject {
 	ui.exportButton = widget.NewButtonWithIcon("خروجی اکسل", theme.DocumentSaveIcon(), ui.onExportToExcel) // آیکن اصلاح شد
 	ui.resetButton = widget.NewButtonWithIcon("پاک کردن جدول", theme.ContentClearIcon(), ui.onResetTable)
+	historyButton := widget.NewButtonWithIcon("تاریخچه", theme.HistoryIcon(), ui.onShowHistory)
 	helpButton := widget.NewButtonWithIcon("راهنما", theme.HelpIcon(), ui.onShowHelp)
 	aboutButton := widget.NewButtonWithIcon("درباره", theme.InfoIcon(), ui.onShowAbout)
 	logoutButton := widget.NewButtonWithIcon("خروج از حساب", theme.LogoutIcon(), ui.onLogout)
+	changePasswordButton := widget.NewButtonWithIcon("تغییر رمز عبور", theme.AccountIcon(), ui.onChangePassword)
 	var leftButtonWidgets []fyne.CanvasObject
-	if ui.User.Role == "admin" {
+	if auth.Can(ui.User, auth.CapManageLinks) {
 		ui.manageLinksButton = widget.NewButtonWithIcon("مدیریت لینک‌ها", theme.SettingsIcon(), ui.onManageCloudLinks)
 		leftButtonWidgets = append(leftButtonWidgets, ui.manageLinksButton)
-	} else {
+	}
+	if auth.Can(ui.User, auth.CapManageUsers) {
+		manageUsersButton := widget.NewButtonWithIcon("مدیریت کاربران", theme.AccountIcon(), ui.onManageUsers)
+		sessionSettingsButton := widget.NewButtonWithIcon("تنظیمات نشست", theme.SettingsIcon(), ui.onSessionSettings)
+		leftButtonWidgets = append(leftButtonWidgets, manageUsersButton, sessionSettingsButton)
+	}
+	if auth.Can(ui.User, auth.CapManageOrg) {
+		orgButton := widget.NewButtonWithIcon("ساختار سازمانی", theme.ListIcon(), ui.onManageOrgStructure)
+		leftButtonWidgets = append(leftButtonWidgets, orgButton)
+	}
+	if auth.Can(ui.User, auth.CapCloudUpdate) {
 		ui.updateCloudButton = widget.NewButtonWithIcon("به‌روزرسانی از سرور", theme.DownloadIcon(), ui.onUpdateFromCloud)
 		leftButtonWidgets = append(leftButtonWidgets, ui.updateCloudButton)
 	}
-	leftButtonWidgets = append(leftButtonWidgets, ui.exportButton)
-	rightButtonWidgetsElements := []fyne.CanvasObject{ui.resetButton, helpButton, aboutButton, logoutButton}
+	if auth.Can(ui.User, auth.CapExport) {
+		leftButtonWidgets = append(leftButtonWidgets, ui.exportButton)
+	}
+	leftButtonWidgets = append(leftButtonWidgets, historyButton)
+	if auth.Can(ui.User, auth.CapViewAudit) {
+		auditButton := widget.NewButtonWithIcon("گزارش حسابرسی", theme.DocumentIcon(), ui.onShowAuditLog)
+		leftButtonWidgets = append(leftButtonWidgets, auditButton)
+	}
+	if auth.Can(ui.User, auth.CapViewSummary) {
+		summaryButton := widget.NewButtonWithIcon("خلاصه همه واحدها", theme.GridIcon(), ui.onShowDepartmentsSummary)
+		leftButtonWidgets = append(leftButtonWidgets, summaryButton)
+	}
+	rightButtonWidgetsElements := []fyne.CanvasObject{helpButton, aboutButton, changePasswordButton, logoutButton}
+	if auth.Can(ui.User, auth.CapEditAllocation) {
+		ui.undoButton = widget.NewButtonWithIcon("بازگشت", theme.ContentUndoIcon(), ui.onUndo)
+		ui.redoButton = widget.NewButtonWithIcon("انجام مجدد", theme.ContentRedoIcon(), ui.onRedo)
+		ui.undoButton.Disable()
+		ui.redoButton.Disable()
+		rightButtonWidgetsElements = append([]fyne.CanvasObject{ui.undoButton, ui.redoButton, ui.resetButton}, rightButtonWidgetsElements...)
+	}
 
 	ui.exportButton.Disable()
 	ui.resetButton.Disable()
@@ -296,38 +600,118 @@ func (ui *MainUI) createBottomButtons() fyne.CanvasObject {
 		container.NewHBox(combinedRightWidgets...),
 	)
 }
-func (ui *MainUI) getAccessibleDepartmentShifts() []string {
-	var accessible []string
-	if ui.User.Role == "admin" {
-		return core.ManageableDepartments
+
+// effectiveStrategy روش توزیع واحد را برمی‌گرداند؛ مقدار خالی یعنی تقسیم مساوی.
+func effectiveStrategy(data *core.DepartmentData) core.AllocationStrategy {
+	if data == nil || data.Strategy == "" {
+		return core.StrategyEqual
 	}
-	baseDept := ui.User.Department
-	if baseDept == "فنی مهندسی" {
-		accessible = []string{
-			"تراشکاری - شیفتی", "دفتر فنی - ثابت", "برق - ثابت", "برق - شیفتی",
-			"مکانیک - ثابت", "مکانیک - شیفتی", "نت - ثابت", "تأسیسات - ثابت",
-			"تأسیسات - شیفتی", "رؤسا و سرپرستان فنی مهندسی - ثابت",
+	return data.Strategy
+}
+func (ui *MainUI) onStrategyChanged(selected string) {
+	ui.idle.Touch()
+	if ui.currentDepartmentData == nil {
+		return
+	}
+	strategy, ok := core.AllocationStrategyByDisplayName(selected)
+	if !ok || strategy == effectiveStrategy(ui.currentDepartmentData) {
+		return
+	}
+	ui.commitPendingEdit()
+	ui.currentDepartmentData.Strategy = strategy
+	ui.updateFillCapInputState()
+	ui.reallocateHours(audit.SourceSettingsEdit)
+}
+func (ui *MainUI) onFillCapChanged(s string) {
+	ui.idle.Touch()
+	if ui.currentDepartmentData == nil {
+		return
+	}
+	newCap, err := strconv.Atoi(s)
+	if err != nil || newCap < 0 {
+		if s != "" {
+			ui.fillCapInput.SetText(strconv.Itoa(ui.currentDepartmentData.FillCapHours))
 		}
-	} else if baseDept == "سرمایه های انسانی" {
-		accessible = []string{
-			"سرمایه های انسانی - ثابت", "سرمایه های انسانی - شیفتی", "مدیران و رؤسا - ثابت",
+		return
+	}
+	if ui.currentDepartmentData.FillCapHours == newCap {
+		return
+	}
+	ui.beginTypedEdit("fill-cap", audit.SourceSettingsEdit)
+	ui.currentDepartmentData.FillCapHours = newCap
+	if effectiveStrategy(ui.currentDepartmentData) == core.StrategyFillToCap {
+		ui.reallocateTyped()
+	}
+}
+func (ui *MainUI) onDepartmentLimitChanged(input *widget.Entry, key, s string, field func(*core.DepartmentData) *int) {
+	ui.idle.Touch()
+	if ui.currentDepartmentData == nil || !auth.Can(ui.User, auth.CapEditBudget) {
+		return
+	}
+	target := field(ui.currentDepartmentData)
+	newValue, err := strconv.Atoi(s)
+	if err != nil || newValue < 0 {
+		if s != "" {
+			input.SetText(strconv.Itoa(*target))
 		}
+		return
+	}
+	if *target == newValue {
+		return
+	}
+	ui.beginTypedEdit(key, audit.SourceSettingsEdit)
+	*target = newValue
+	ui.reallocateTyped()
+}
+
+// updateDefaultLimitInputsState حداقل و سقف پیش‌فرض را فقط برای کاربران دارای مجوز ویرایش سرانه و پس از انتخاب واحد فعال می‌کند.
+func (ui *MainUI) updateDefaultLimitInputsState() {
+	if ui.defaultMinInput == nil {
+		return
+	}
+	if ui.currentDepartmentData != nil && auth.Can(ui.User, auth.CapEditBudget) {
+		ui.defaultMinInput.Enable()
+		ui.defaultMaxInput.Enable()
 	} else {
-		if shifts, ok := core.DepartmentShifts[baseDept]; ok {
-			for _, shift := range shifts {
-				accessible = append(accessible, baseDept+" - "+shift)
-			}
-		} else {
-			fmt.Printf("خطا: واحد سازمانی '%s' برای کاربر تعریف نشده است.\n", baseDept)
-		}
+		ui.defaultMinInput.Disable()
+		ui.defaultMaxInput.Disable()
+	}
+}
+func (ui *MainUI) updateFillCapInputState() {
+	if ui.currentDepartmentData != nil && effectiveStrategy(ui.currentDepartmentData) == core.StrategyFillToCap && auth.Can(ui.User, auth.CapEditAllocation) {
+		ui.fillCapInput.Enable()
+	} else {
+		ui.fillCapInput.Disable()
+	}
+}
+
+// getAccessibleDepartmentShifts واحد-شیفت‌های قابل دسترسی کاربر را از سرویس مجوزها می‌گیرد.
+func (ui *MainUI) getAccessibleDepartmentShifts() []string {
+	return auth.AccessibleDepartmentShifts(ui.User)
+}
+
+// departmentShiftForLabel کلید واحد-شیفت متناظر با گزینه انتخاب شده در فهرست واحدها را برمی‌گرداند.
+func (ui *MainUI) departmentShiftForLabel(label string) string {
+	if deptShift, ok := ui.deptShiftByLabel[label]; ok {
+		return deptShift
+	}
+	return label
+}
+
+// authorize دسترسی کاربر را از طریق سرویس مجوزها بررسی می‌کند و در صورت نداشتن مجوز، خطا نمایش می‌دهد.
+func (ui *MainUI) authorize(capability auth.Capability, deptShift string) bool {
+	if err := auth.Authorize(ui.User, capability, deptShift); err != nil {
+		dialog.ShowError(err, ui.Window)
+		return false
 	}
-	sort.Strings(accessible)
-	return accessible
+	return true
 }
 func (ui *MainUI) onDepartmentChanged(selectedDeptShift string) {
+	ui.idle.Touch()
+	ui.commitPendingEdit()
 	if selectedDeptShift == "" || selectedDeptShift == ui.deptComboBox.PlaceHolder || selectedDeptShift == "-- هیچ واحدی قابل دسترسی نیست --" {
 		ui.clearUIForNoDepartment()
-		if ui.User.Role == "admin" && ui.adminManualEmployeesInput != nil {
+		if ui.adminManualEmployeesInput != nil {
 			ui.adminManualEmployeesInput.Disable()
 			ui.adminCreateTableButton.Disable()
 			ui.adminImportExcelButton.Disable()
@@ -338,13 +722,14 @@ func (ui *MainUI) onDepartmentChanged(selectedDeptShift string) {
 	fmt.Println("واحد انتخاب شده توسط کاربر:", selectedDeptShift)
 	ui.loadDepartmentDataByName(selectedDeptShift)
 	ui.refreshUIForCurrentDepartment()
-	if ui.User.Role == "admin" && ui.adminManualEmployeesInput != nil {
+	if ui.adminManualEmployeesInput != nil {
 		ui.adminManualEmployeesInput.Enable()
 		ui.adminCreateTableButton.Enable()
 		ui.adminImportExcelButton.Enable()
 	}
 }
 func (ui *MainUI) loadDepartmentDataByName(deptShiftName string) {
+	ui.lastDiagnostics = nil
 	data, exists := core.AllDepartmentsData[deptShiftName]
 	if !exists {
 		fmt.Printf("داده‌ای برای واحد '%s' در حافظه یافت نشد. یک ورودی جدید ایجاد می‌شود.\n", deptShiftName)
@@ -352,7 +737,7 @@ func (ui *MainUI) loadDepartmentDataByName(deptShiftName string) {
 			DepartmentShiftName: deptShiftName,
 			TotalHours:          0,
 			ProductionDays:      0,
-			MonthName:           "",
+			Period:              core.Period{},
 			Employees:           []core.Employee{},
 		}
 		core.AllDepartmentsData[deptShiftName] = newData
@@ -363,18 +748,18 @@ func (ui *MainUI) loadDepartmentDataByName(deptShiftName string) {
 }
 func (ui *MainUI) clearUIForNoDepartment() {
 	ui.currentDepartmentData = nil
+	ui.lastDiagnostics = nil
 	ui.currentEmployees.Set(nil)
 	ui.totalHoursInput.SetText("0")
 	ui.productionDaysInput.SetText("0")
-	if ui.User.Role != "admin" {
-		ui.totalHoursInput.Disable()
-	} else {
-		ui.totalHoursInput.SetText("0")
-		ui.totalHoursInput.Disable()
-	}
+	ui.totalHoursInput.Disable()
 	ui.productionDaysInput.Disable()
+	ui.strategySelect.Disable()
+	ui.fillCapInput.Disable()
+	ui.updateDefaultLimitInputsState()
 	ui.employeesTable.Refresh()
 	ui.updateSummaryLabel()
+	ui.updateLastSavedLabel()
 	ui.exportButton.Disable()
 	ui.resetButton.Disable()
 	if ui.updateCloudButton != nil {
@@ -389,6 +774,7 @@ func (ui *MainUI) clearUIForNoDepartment() {
 	if ui.adminImportExcelButton != nil {
 		ui.adminImportExcelButton.Disable()
 	}
+	ui.updateUndoRedoState()
 }
 func (ui *MainUI) tableDataLength() (rows int, cols int) {
 	length := ui.currentEmployees.Length() // اصلاح شد
@@ -431,8 +817,17 @@ func (ui *MainUI) tableUpdateCell(id widget.TableCellID, template fyne.CanvasObj
 			if err != nil {
 				return fmt.Errorf("باید عدد باشد")
 			}
-			if val < 0 || val > 999 {
-				return fmt.Errorf("ساعت باید بین 0 و 999 باشد")
+			if val < 0 || val > allocation.DefaultMaxHoursPerEmployee {
+				return fmt.Errorf("ساعت باید بین 0 و %d باشد", allocation.DefaultMaxHoursPerEmployee)
+			}
+			if ui.currentDepartmentData != nil {
+				minHours, maxHours := allocation.EffectiveLimits(*emp, *ui.currentDepartmentData, allocation.DefaultConstraints())
+				if val < minHours {
+					return fmt.Errorf("حداقل ساعت این فرد %d است", minHours)
+				}
+				if maxHours != allocation.Unlimited && val > maxHours {
+					return fmt.Errorf("سقف ساعت این فرد %d است", maxHours)
+				}
 			}
 			return nil
 		}
@@ -442,83 +837,74 @@ func (ui *MainUI) tableUpdateCell(id widget.TableCellID, template fyne.CanvasObj
 				return
 			}
 
+			ui.idle.Touch()
 			if entry.Validate() == nil {
 				newHours, _ := strconv.Atoi(s)
 				if emp.Hours != newHours {
 					isUpdatingHoursFromEntry = true
+					ui.beginTypedEdit("hours:"+emp.ID, audit.SourceTableEdit)
 					emp.Hours = newHours
 					emp.Locked = true
-					ui.reallocateHours()
+					ui.reallocateTyped()
 					isUpdatingHoursFromEntry = false
 				}
 			}
 		}
+		entry.OnSubmitted = func(string) { ui.commitPendingEdit() }
+		if !auth.Can(ui.User, auth.CapEditAllocation) {
+			entry.Disable()
+		}
 		// OnFocusLost حذف شد
 		cellContainer.Objects = []fyne.CanvasObject{entry}
 	case 3:
-		monthToDisplay := emp.MonthType
-		if monthToDisplay == "" && ui.currentDepartmentData != nil {
-			monthToDisplay = ui.currentDepartmentData.MonthName
+		periodToDisplay := emp.Period
+		if periodToDisplay.IsZero() && ui.currentDepartmentData != nil {
+			periodToDisplay = ui.currentDepartmentData.Period
 		}
-		if monthToDisplay == "" {
-			monthToDisplay = core.GetCurrentPersianMonthName()
+		if periodToDisplay.IsZero() {
+			periodToDisplay = core.CurrentPeriod()
 		}
-		monthLabel := widget.NewLabel(monthToDisplay)
+		monthLabel := widget.NewLabel(periodToDisplay.String())
 		monthLabel.Alignment = fyne.TextAlignCenter
 		cellContainer.Objects = []fyne.CanvasObject{monthLabel}
 	case 4:
-		check := widget.NewCheck("", func(locked bool) {
+		// OnChanged پس از SetChecked تنظیم می‌شود تا رسم دوباره جدول فعالیت کاربر حساب نشود.
+		check := widget.NewCheck("", nil)
+		check.SetChecked(emp.Locked)
+		check.OnChanged = func(locked bool) {
 			if emp.Locked != locked {
+				ui.idle.Touch()
+				ui.commitPendingEdit()
 				emp.Locked = locked
-				ui.reallocateHours()
+				ui.reallocateHours(audit.SourceTableEdit)
 			}
-		})
-		check.SetChecked(emp.Locked)
+		}
+		if !auth.Can(ui.User, auth.CapEditAllocation) {
+			check.Disable()
+		}
 		centeredCheck := container.NewCenter(check)
 		cellContainer.Objects = []fyne.CanvasObject{centeredCheck}
 	}
 	cellContainer.Refresh()
 }
-func (ui *MainUI) reallocateHours() {
+func (ui *MainUI) reallocateHours(source audit.Source) {
 	if ui.currentDepartmentData == nil || len(ui.currentDepartmentData.Employees) == 0 {
 		ui.updateSummaryLabel()
 		if ui.currentDepartmentData == nil || len(ui.currentDepartmentData.Employees) == 0 {
 			ui.currentEmployees.Set(nil)
 		}
 		ui.employeesTable.Refresh()
+		ui.saveDepartment(ui.currentDepartmentData, source)
 		return
 	}
-	lockedSum := 0
-	var unlockedIndices []int
-	for i := range ui.currentDepartmentData.Employees {
-		if ui.currentDepartmentData.Employees[i].Locked {
-			lockedSum += ui.currentDepartmentData.Employees[i].Hours
-		} else {
-			unlockedIndices = append(unlockedIndices, i)
-		}
-	}
-	remainingTotalHours := ui.currentDepartmentData.TotalHours - lockedSum
-	if remainingTotalHours < 0 {
-		remainingTotalHours = 0
+	result := allocation.Allocate(*ui.currentDepartmentData, allocation.DefaultConstraints())
+	for i := range result.Employees {
+		ui.currentDepartmentData.Employees[i].Hours = result.Employees[i].Hours
 	}
-	numUnlocked := len(unlockedIndices)
-	baseHoursPerUnlocked := 0
-	extraHoursCount := 0
-	if numUnlocked > 0 {
-		baseHoursPerUnlocked = remainingTotalHours / numUnlocked
-		extraHoursCount = remainingTotalHours % numUnlocked
-	} else if remainingTotalHours > 0 {
-		fmt.Println("هشدار: تمام پرسنل قفل هستند اما هنوز ساعت برای توزیع باقی مانده است.")
-	}
-	extraDistributed := 0
-	for _, idx := range unlockedIndices {
-		allocatedHours := baseHoursPerUnlocked
-		if extraDistributed < extraHoursCount {
-			allocatedHours++
-		}
-		extraDistributed++
-		ui.currentDepartmentData.Employees[idx].Hours = allocatedHours
+	for _, d := range result.Diagnostics {
+		fmt.Println(d.String())
 	}
+	ui.lastDiagnostics = result.Diagnostics
 	var empInterfaces []interface{}
 	for i := range ui.currentDepartmentData.Employees {
 		empInterfaces = append(empInterfaces, &ui.currentDepartmentData.Employees[i])
@@ -529,6 +915,7 @@ func (ui *MainUI) reallocateHours() {
 	}
 	ui.employeesTable.Refresh()
 	ui.updateSummaryLabel()
+	ui.saveDepartment(ui.currentDepartmentData, source)
 }
 func (ui *MainUI) updateSummaryLabel() {
 	if ui.currentDepartmentData == nil {
@@ -542,6 +929,11 @@ func (ui *MainUI) updateSummaryLabel() {
 	}
 	targetHours := ui.currentDepartmentData.TotalHours
 	text := fmt.Sprintf("مجموع ساعات: %d / سرانه: %d", currentAllocatedHours, targetHours)
+	for _, d := range ui.lastDiagnostics {
+		if d.Severity == allocation.SeverityError {
+			text += "\n" + d.Message
+		}
+	}
 	ui.summaryLabel.SetText(text)
 	ui.summaryLabel.Refresh()
 }
@@ -551,6 +943,9 @@ func (ui *MainUI) onUpdateFromCloud() {
 		return
 	}
 	deptShiftName := ui.currentDepartmentData.DepartmentShiftName
+	if !ui.authorize(auth.CapCloudUpdate, deptShiftName) {
+		return
+	}
 	dialog.ShowConfirm("تأیید به‌روزرسانی", fmt.Sprintf("آیا می‌خواهید اطلاعات واحد '%s' را از سرور به‌روزرسانی کنید؟", deptShiftName), func(confirm bool) {
 		if !confirm {
 			return
@@ -561,11 +956,11 @@ func (ui *MainUI) onUpdateFromCloud() {
 			defer progress.Hide()
 			allLinks := cloud.LoadCloudLinks()
 			link, ok := allLinks[deptShiftName]
-			if !ok || link == "" {
+			if !ok || link.URL == "" {
 				dialog.ShowError(fmt.Errorf("لینک دانلود برای واحد '%s' یافت نشد", deptShiftName), ui.Window)
 				return
 			}
-			downloadURL := cloud.ConvertToDownloadLink(link)
+			downloadURL := cloud.ConvertToDownloadLink(link.URL)
 
 			tempFilePath, err := cloud.DownloadToTempFile(downloadURL, "cloud_dl_*.xlsx")
 			if err != nil {
@@ -573,37 +968,70 @@ func (ui *MainUI) onUpdateFromCloud() {
 				return
 			}
 			defer os.Remove(tempFilePath)
-			seraneh, prodDays, monthF3, err := excel.ReadBasicDataFromExcel(tempFilePath)
+			report := &excel.Report{}
+			seraneh, prodDays, periodF3, err := excel.ReadBasicDataFromExcel(tempFilePath, link.Layout, report)
 			if err != nil {
 				dialog.ShowError(fmt.Errorf("خطا در خواندن اطلاعات پایه از اکسل (%s): %w", filepath.Base(tempFilePath), err), ui.Window)
 				return
 			}
-			employees, err := excel.ReadEmployeesFromExcel(tempFilePath, deptShiftName)
+			employees, err := excel.ReadEmployeesFromExcel(tempFilePath, deptShiftName, link.Layout, report)
 			if err != nil {
 				dialog.ShowError(fmt.Errorf("خطا در خواندن لیست پرسنل از اکسل (%s) برای واحد '%s': %w", filepath.Base(tempFilePath), deptShiftName, err), ui.Window)
 				return
 			}
 
-			finalMonthName := monthF3
-			if finalMonthName == "" {
-				finalMonthName = core.GetCurrentPersianMonthName()
-				dialog.ShowInformation("هشدار ماه", fmt.Sprintf("مقدار ماه در فایل اکسل (سلول %s) نامعتبر یا خالی است.\n از ماه جاری سیستم (%s) استفاده خواهد شد.", core.MonthCell, finalMonthName), ui.Window)
+			imported := core.ImportedDepartment{
+				TotalHours:     seraneh,
+				ProductionDays: prodDays,
+				Period:         periodF3,
+				Employees:      employees,
 			}
-			// Update UI directly
-			ui.currentDepartmentData.Employees = employees
-			ui.totalHoursInput.SetText(strconv.Itoa(seraneh))
-			ui.productionDaysInput.SetText(strconv.Itoa(prodDays))
-			ui.reallocateHours()
-			ui.exportButton.Enable()
-			dialog.ShowInformation("موفقیت", fmt.Sprintf("اطلاعات واحد '%s' با موفقیت به‌روز شد.", deptShiftName), ui.Window)
+			fyne.Do(func() {
+				data := ui.currentDepartmentData
+				if data == nil || data.DepartmentShiftName != deptShiftName {
+					return
+				}
+				showPreview := func() {
+					incoming := core.DepartmentData{DepartmentShiftName: deptShiftName}
+					core.ApplyImport(&incoming, imported)
+					preview := core.PreviewUpdate(*data, incoming)
+					ShowUpdatePreviewDialog(ui.Window, deptShiftName, preview, func(keepLocks bool) {
+						ui.applyCloudUpdate(data, imported, keepLocks)
+					})
+				}
+				if report.HasIssues() {
+					ShowValidationReportDialog(ui.Window, fmt.Sprintf("گزارش بررسی فایل واحد '%s'", deptShiftName), report, showPreview)
+					return
+				}
+				showPreview()
+			})
 		}()
 	}, ui.Window)
 }
+
+// applyCloudUpdate داده دریافت شده از سرور را (پس از تأیید پیش‌نمایش) با همان ApplyImport ورود اطلاعات ادمین
+// روی واحد اعمال می‌کند. با keepLocks، ساعات پرسنل قفل شده‌ای که با همان کد پرسنلی در لیست جدید هستند حفظ می‌شوند.
+func (ui *MainUI) applyCloudUpdate(data *core.DepartmentData, imported core.ImportedDepartment, keepLocks bool) {
+	if data != ui.currentDepartmentData || !ui.authorize(auth.CapCloudUpdate, data.DepartmentShiftName) {
+		return
+	}
+	ui.commitPendingEdit()
+	if keepLocks {
+		imported.Employees = core.KeepLockedHours(data.Employees, imported.Employees)
+	}
+	core.ApplyImport(data, imported)
+	ui.refreshUIForCurrentDepartment()
+	ui.reallocateHours(audit.SourceCloudUpdate)
+	dialog.ShowInformation("موفقیت", fmt.Sprintf("اطلاعات واحد '%s' با موفقیت به‌روز شد.", data.DepartmentShiftName), ui.Window)
+}
 func (ui *MainUI) onExportToExcel() {
 	if ui.currentDepartmentData == nil || len(ui.currentDepartmentData.Employees) == 0 {
 		dialog.ShowInformation("خطا", "داده‌ای برای خروجی گرفتن وجود ندارد.", ui.Window)
 		return
 	}
+	if !ui.authorize(auth.CapExport, ui.currentDepartmentData.DepartmentShiftName) {
+		return
+	}
 	currentAllocated := 0
 	for _, emp := range ui.currentDepartmentData.Employees {
 		currentAllocated += emp.Hours
@@ -612,12 +1040,12 @@ func (ui *MainUI) onExportToExcel() {
 		dialog.ShowInformation("خطا در تخصیص", fmt.Sprintf("مجموع ساعات تخصیص یافته (%d) با سرانه کل (%d) برابر نیست. لطفاً ساعات را بررسی کنید.", currentAllocated, ui.currentDepartmentData.TotalHours), ui.Window)
 		return
 	}
-	monthForFile := ui.currentDepartmentData.MonthName
-	if monthForFile == "" {
-		monthForFile = core.GetCurrentPersianMonthName()
+	periodForFile := ui.currentDepartmentData.Period
+	if periodForFile.IsZero() {
+		periodForFile = core.CurrentPeriod()
 	}
 	dateStr := time.Now().Format("2006-01-02")
-	defaultFileName := fmt.Sprintf("%s - %s - %s.xlsx", ui.currentDepartmentData.DepartmentShiftName, monthForFile, dateStr)
+	defaultFileName := fmt.Sprintf("%s - %s - %s.xlsx", ui.currentDepartmentData.DepartmentShiftName, periodForFile, dateStr)
 	defaultFileName = strings.ReplaceAll(strings.ReplaceAll(defaultFileName, "/", "_"), "\\", "_")
 	fileSaveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, errDialog error) {
 		if errDialog != nil {
@@ -633,13 +1061,13 @@ func (ui *MainUI) onExportToExcel() {
 			{"نام واحد", "نام پرسنل", "کد پرسنلی", "ساعت اضافه کاری", "ماه"},
 		}
 		deptName := ui.currentDepartmentData.DepartmentShiftName
-		monthAssigned := ui.currentDepartmentData.MonthName
-		if monthAssigned == "" {
-			monthAssigned = core.GetCurrentPersianMonthName()
+		periodAssigned := ui.currentDepartmentData.Period
+		if periodAssigned.IsZero() {
+			periodAssigned = core.CurrentPeriod()
 		}
 		for _, emp := range ui.currentDepartmentData.Employees {
 			dataForExcel = append(dataForExcel, []interface{}{
-				deptName, emp.Name, emp.ID, emp.Hours, monthAssigned,
+				deptName, emp.Name, emp.ID, emp.Hours, periodAssigned.String(),
 			})
 		}
 		errWrite := excel.WriteDataToExcel(writer, dataForExcel)
@@ -647,6 +1075,15 @@ func (ui *MainUI) onExportToExcel() {
 			dialog.ShowError(fmt.Errorf("خطا در ذخیره فایل اکسل: %w", errWrite), ui.Window)
 			return
 		}
+
+		// تخصیص خروجی گرفته شده به عنوان نسخه نهایی دوره بایگانی می‌شود.
+		finalized := *ui.currentDepartmentData
+		finalized.Period = periodAssigned
+		if errArchive := storage.ArchiveDepartmentData(&finalized, ui.User.Username); errArchive != nil {
+			fyne.LogError("Failed to archive exported allocation", errArchive)
+			dialog.ShowInformation("هشدار بایگانی", fmt.Sprintf("فایل اکسل ذخیره شد اما ثبت در تاریخچه انجام نشد: %v", errArchive), ui.Window)
+			return
+		}
 		dialog.ShowInformation("موفقیت", "فایل اکسل با موفقیت ذخیره شد:\n"+writer.URI().Path(), ui.Window)
 	}, ui.Window)
 	fileSaveDialog.SetFileName(defaultFileName)
@@ -658,10 +1095,14 @@ func (ui *MainUI) onResetTable() {
 		return
 	}
 	deptName := ui.currentDepartmentData.DepartmentShiftName
+	if !ui.authorize(auth.CapEditAllocation, deptName) {
+		return
+	}
 	dialog.ShowConfirm("تأیید پاک کردن", fmt.Sprintf("آیا مطمئن هستید که می‌خواهید جدول واحد '%s' را پاک کنید؟\n(سرانه، روزهای تولید و لیست پرسنل صفر خواهند شد)", deptName), func(confirm bool) {
 		if !confirm {
 			return
 		}
+		ui.commitPendingEdit()
 		originalData, ok := core.AllDepartmentsData[deptName]
 		if !ok {
 			dialog.ShowError(fmt.Errorf("خطای داخلی: داده‌های واحد '%s' برای ریست یافت نشد.", deptName), ui.Window)
@@ -669,41 +1110,74 @@ func (ui *MainUI) onResetTable() {
 		}
 		originalData.TotalHours = 0
 		originalData.ProductionDays = 0
-		originalData.MonthName = ""
+		originalData.Period = core.Period{}
 		originalData.Employees = []core.Employee{}
 		ui.totalHoursInput.SetText("0")
 		ui.productionDaysInput.SetText("0")
 		ui.currentEmployees.Set(nil)
-		ui.reallocateHours()
+		ui.reallocateHours(audit.SourceReset)
 		ui.exportButton.Disable()
 		dialog.ShowInformation("پاک شد", fmt.Sprintf("جدول واحد '%s' با موفقیت پاک شد.", deptName), ui.Window)
 	}, ui.Window)
 }
+func (ui *MainUI) onShowHistory() {
+	ShowHistoryDialog(ui.Window, ui.getAccessibleDepartmentShifts())
+}
+func (ui *MainUI) onShowDepartmentsSummary() {
+	if !ui.authorize(auth.CapViewSummary, "") {
+		return
+	}
+	ShowDepartmentsSummaryDialog(ui.Window, ui.getAccessibleDepartmentShifts())
+}
+func (ui *MainUI) onShowAuditLog() {
+	if !ui.authorize(auth.CapViewAudit, "") {
+		return
+	}
+	ShowAuditLogDialog(ui.Window)
+}
 func (ui *MainUI) onShowHelp() {
 	seranehInfo := fmt.Sprintf("(از سلول %s)", core.SeranehCell)
 	prodDaysInfo := fmt.Sprintf("(از سلول %s)", core.ProductionDaysCell)
 	monthInfo := fmt.Sprintf("(از سلول %s)", core.MonthCell)
 
 	var helpText string
-	if ui.User.Role == "admin" {
+	if auth.Can(ui.User, auth.CapImport) {
 		helpText = fmt.Sprintf(`راهنمای مدیر:
 1. لینک‌ها: تنظیم لینک دانلود اکسل واحدها (از طریق دکمه "مدیریت لینک‌ها").
-2. فایل‌های اکسل ورودی: باید شامل ستون A برای "نام واحد"، B برای "نام پرسنل" و C برای "کد پرسنلی" باشند. سرانه کل در %s، روزهای تولید در %s و نام ماه تخصیص در %s فایل اکسل قرار گیرد.
-3. ورود دستی/اکسل: برای وارد کردن اطلاعات به صورت دستی یا از طریق فایل اکسل.
+2. فایل‌های اکسل ورودی: ستون‌ها بر اساس عنوان ردیف اول (به هر ترتیبی) شناسایی می‌شوند و ستون‌های "نام واحد"، "نام پرسنل" و "کد پرسنلی" (یا "شماره پرسنلی") الزامی هستند. ستون‌های اختیاری "وزن"، "روزهای کارکرد" و "سابقه" برای روش‌های توزیع غیرمساوی و "حداقل ساعت" و "سقف ساعت" برای محدودیت‌های هر نفر خوانده می‌شوند. عناوین مترادف اضافی در فایل column_headers.json کنار برنامه قابل تعریف است. سرانه کل در %s، روزهای تولید در %s و دوره تخصیص (مثلا "فروردین ۱۴۰۴" یا "۱۴۰۴/۰۱") در %s فایل اکسل قرار گیرد.
+   فایل چند واحدی: اگر فایل شیتی با نام "سرانه" (یا "بودجه") داشته باشد، هر ردیف آن شامل واحد-شیفت (ستون A)، سرانه (B)، روزهای تولید (C) و ماه (D) است و هر واحد با مقادیر ردیف خود وارد می‌شود؛ در غیر این صورت سلول‌های F فقط برای اولین واحد فایل استفاده می‌شوند.
+3. ورود دستی/اکسل: برای وارد کردن اطلاعات به صورت دستی یا از طریق فایل اکسل. پیش از اعمال اطلاعات فایل، گزارش خطاها و هشدارها (مانند ردیف‌های بدون نام یا کد پرسنلی و سلول‌های سرانه خالی یا نامعتبر) با نام شیت و آدرس سلول نمایش داده می‌شود.
 4. ویرایش سرانه: سرانه کل برای واحد انتخاب شده توسط ادمین قابل ویرایش است.
 5. بررسی و خروجی: مشاهده و بررسی تخصیص‌ها. خروجی اکسل (ماه بر اساس %s).
-6. پاک کردن جدول: حذف کامل اطلاعات برای واحد انتخاب شده.`,
+6. پاک کردن جدول: حذف کامل اطلاعات برای واحد انتخاب شده.
+7. تاریخچه: هر خروجی اکسل به عنوان تخصیص نهایی دوره بایگانی می‌شود. در "تاریخچه" ساعات هر نفر در ماه‌های اخیر، افزایش‌های ناگهانی و مقایسه واحدها دیده می‌شود.
+8. کاربران: از طریق "مدیریت کاربران" کاربر جدید ایجاد، نقش و دپارتمان را ویرایش، رمز را بازنشانی یا حساب را غیرفعال کنید. کاربری که رمزش بازنشانی شده در ورود بعدی ملزم به تغییر آن است. با "دسترسی واحدها" می‌توان فهرست واحد-شیفت‌های هر کاربر را مستقل از دپارتمان تعیین کرد. مدت عدم فعالیت تا خروج خودکار در "تنظیمات نشست" تعیین می‌شود (صفر = غیرفعال).
+9. نقش‌ها: توانمندی هر نقش (ویرایش سرانه، ورود اطلاعات، مدیریت لینک‌ها، خروجی، فقط مشاهده و ...) در فایل roles.json کنار برنامه قابل تغییر است.
+10. گزارش حسابرسی: هر تغییر ساعات، سرانه، تنظیمات توزیع، به‌روزرسانی از سرور، پاک کردن جدول و ورود اطلاعات با نام کاربر و زمان در گزارشی زنجیره‌ای ثبت می‌شود و با فیلتر قابل مشاهده و خروجی اکسل است. ویرایش یا حذف ردیف‌ها بدون دسترسی به کلید نصب معمولا در بررسی زنجیره مشخص می‌شود، ولی این بررسی جایگزین محدود کردن دسترسی به پوشه برنامه نیست.
+11. بازگشت و انجام مجدد: با Ctrl+Z آخرین تغییر جدول واحد جاری (ساعت، قفل، سرانه، پاک کردن یا به‌روزرسانی از سرور) برگردانده و با Ctrl+Y دوباره اعمال می‌شود. تایپ پشت سر هم در یک خانه (مانند "120") یک تغییر حساب می‌شود.
+12. ساختار سازمانی: واحدها، شیفت‌ها، نام‌های نمایشی و گروه‌های ویژه (مانند "فنی مهندسی") از طریق "ساختار سازمانی" ویرایش و در فایل org_structure.json کنار برنامه ذخیره می‌شوند.`,
 			core.SeranehCell, core.ProductionDaysCell, core.MonthCell, core.MonthCell)
+	} else if !auth.Can(ui.User, auth.CapEditAllocation) {
+		helpText = `راهنمای کاربر فقط مشاهده:
+1. انتخاب واحد: جدول تخصیص هر واحد قابل دسترسی را از فهرست "واحد سازمانی" انتخاب کنید.
+2. فقط مشاهده: ساعات، قفل‌ها، سرانه و روش توزیع قابل تغییر نیستند.
+3. خلاصه همه واحدها: جمع پرسنل، سرانه و ساعات تخصیص یافته همه واحدها و اختلاف آن‌ها با سرانه را نشان می‌دهد.
+4. تاریخچه: ساعات هر نفر در ماه‌های اخیر و مقایسه واحدها بر اساس تخصیص‌های نهایی.`
 	} else {
 		helpText = fmt.Sprintf(`راهنمای بالاترین مقام واحد:
-1. دریافت اطلاعات: با کلیک بر "به‌روزرسانی از سرور"، لیست پرسنل، سرانه، روزهای تولید و ماه تخصیص از سرور خوانده می‌شود. (سرانه از %s، روزهای تولید از %s، ماه از %s).
+1. دریافت اطلاعات: با کلیک بر "به‌روزرسانی از سرور"، لیست پرسنل، سرانه، روزهای تولید و ماه تخصیص از سرور خوانده می‌شود. (سرانه از %s، روزهای تولید از %s، ماه از %s). پیش از اعمال، پرسنل جدید، حذف شده و تغییر نام یافته و تغییر سرانه و روزهای تولید نمایش داده می‌شود و می‌توانید ساعات قفل شده پرسنل موجود را حفظ کنید.
 2. تخصیص ساعات: فقط ستون "ساعت اضافه کاری" قابل ویرایش است. مجموع باید با سرانه برابر بماند.
 3. ماه تخصیص: ماه تخصیص یافته (از سرور) در ستون "ماه تخصیص" نمایش داده می‌شود و قابل ویرایش نیست.
 4. قفل کردن ساعت: با تیک ستون "قفل"، ساعت پرسنل ثابت می‌ماند.
-5. بررسی نهایی: برچسب "مجموع ساعات" باید نشان دهد که مجموع با سرانه برابر است.
-6. خروجی اکسل: پس از تخصیص صحیح، خروجی بگیرید (ماه فایل بر اساس ماه سرور خواهد بود).
-7. پاک کردن جدول: حذف اطلاعات جدول فعلی برای بارگذاری مجدد.
-توجه: سرانه، روز تولید، ماه و لیست پرسنل قابل ویرایش نیستند.`, seranehInfo, prodDaysInfo, monthInfo)
+5. روش توزیع: سرانه باقیمانده می‌تواند مساوی، متناسب با وزن، متناسب با روزهای کارکرد، مساوی با باقیمانده بر اساس سابقه، یا با پر کردن هر نفر تا سقف تعیین شده توزیع شود.
+6. بررسی نهایی: برچسب "مجموع ساعات" باید نشان دهد که مجموع با سرانه برابر است.
+7. خروجی اکسل: پس از تخصیص صحیح، خروجی بگیرید (ماه فایل بر اساس ماه سرور خواهد بود).
+8. پاک کردن جدول: حذف اطلاعات جدول فعلی برای بارگذاری مجدد.
+9. تاریخچه: ساعات هر نفر در ماه‌های اخیر (از روی خروجی‌های گرفته شده) و افزایش‌های ناگهانی را نشان می‌دهد.
+10. تغییر رمز عبور: با دکمه "تغییر رمز عبور" می‌توانید رمز خود را تغییر دهید. پس از بازنشانی رمز توسط مدیر، در ورود بعدی تغییر رمز الزامی است.
+11. خروج خودکار: پس از مدتی عدم فعالیت (پیش‌فرض %d دقیقه، قابل تغییر توسط مدیر)، ابتدا هشدار داده می‌شود و سپس با ذخیره تغییرات از حساب خارج می‌شوید.
+12. بازگشت و انجام مجدد: با Ctrl+Z (یا دکمه "بازگشت") آخرین تغییر جدول برگردانده و با Ctrl+Y دوباره اعمال می‌شود. تایپ پشت سر هم در یک خانه (مانند "120") یک تغییر حساب می‌شود.
+توجه: سرانه، روز تولید، ماه و لیست پرسنل قابل ویرایش نیستند.`, seranehInfo, prodDaysInfo, monthInfo, config.DefaultIdleTimeoutMinutes)
 	}
 	displayHelpText := strings.ReplaceAll(helpText, "<b>", "")
 	displayHelpText = strings.ReplaceAll(displayHelpText, "</b>", "")
@@ -716,7 +1190,7 @@ func (ui *MainUI) onShowHelp() {
 	helpDialogContent := widget.NewLabel(displayHelpText)
 	helpDialogContent.Wrapping = fyne.TextWrapWord
 
-	dialog.ShowCustom("راهنما", "بستن", container.NewScroll(helpDialogContent), ui.Window)
+	dialog.ShowCustom("راهنما", "بستن", trackActivity(container.NewScroll(helpDialogContent)), ui.Window)
 }
 func (ui *MainUI) onShowAbout() {
 	iconResource := fyne.NewStaticResource("enterprise.ico", resources.AppIconData)
@@ -737,6 +1211,10 @@ func (ui *MainUI) onShowAbout() {
 func (ui *MainUI) onLogout() {
 	dialog.ShowConfirm("خروج از حساب", "آیا مطمئن هستید که می‌خواهید از حساب کاربری خود خارج شوید؟", func(confirm bool) {
 		if confirm {
+			// خروج صریح، توکن‌های "مرا به خاطر بسپار" کاربر را باطل می‌کند
+			if err := auth.RevokeSessions(ui.User.Username); err != nil {
+				fmt.Printf("هشدار: ابطال نشست‌های کاربر '%s' انجام نشد: %v\n", ui.User.Username, err)
+			}
 			if ui.logoutHandler != nil {
 				ui.logoutHandler()
 			} else {
@@ -751,6 +1229,9 @@ func (ui *MainUI) onAdminCreateTable() {
 		dialog.ShowError(fmt.Errorf("ابتدا یک واحد سازمانی را برای ایجاد جدول انتخاب کنید"), ui.Window)
 		return
 	}
+	if !ui.authorize(auth.CapImport, ui.currentDepartmentData.DepartmentShiftName) {
+		return
+	}
 	if err := ui.adminManualEmployeesInput.Validate(); err != nil {
 		dialog.ShowError(fmt.Errorf("تعداد پرسنل نامعتبر است: %w", err), ui.Window)
 		return
@@ -768,6 +1249,7 @@ func (ui *MainUI) onAdminCreateTable() {
 		if !confirm {
 			return
 		}
+		ui.commitPendingEdit()
 		dataToUpdate, ok := core.AllDepartmentsData[deptName]
 		if !ok {
 			dialog.ShowError(fmt.Errorf("خطای داخلی: داده‌های واحد '%s' یافت نشد.", deptName), ui.Window)
@@ -775,15 +1257,15 @@ func (ui *MainUI) onAdminCreateTable() {
 		}
 		dataToUpdate.TotalHours = totalHours
 		dataToUpdate.ProductionDays = 0
-		dataToUpdate.MonthName = core.GetCurrentPersianMonthName()
+		dataToUpdate.Period = core.CurrentPeriod()
 		dataToUpdate.Employees = make([]core.Employee, numEmployees)
 		for i := 0; i < numEmployees; i++ {
 			dataToUpdate.Employees[i] = core.Employee{
-				Name:      fmt.Sprintf("پرسنل جدید %d", i+1),
-				ID:        fmt.Sprintf("%04d", 1000+i+(time.Now().Second()%100)),
-				Hours:     0,
-				Locked:    false,
-				MonthType: dataToUpdate.MonthName,
+				Name:   fmt.Sprintf("پرسنل جدید %d", i+1),
+				ID:     fmt.Sprintf("%04d", 1000+i+(time.Now().Second()%100)),
+				Hours:  0,
+				Locked: false,
+				Period: dataToUpdate.Period,
 			}
 		}
 
@@ -795,12 +1277,15 @@ func (ui *MainUI) onAdminCreateTable() {
 			empInterfaces = append(empInterfaces, &dataToUpdate.Employees[i])
 		}
 		ui.currentEmployees.Set(empInterfaces)
-		ui.reallocateHours()
+		ui.reallocateHours(audit.SourceAdminCreateTable)
 		ui.exportButton.Enable()
 		dialog.ShowInformation("موفقیت", fmt.Sprintf("جدول دستی برای واحد '%s' با موفقیت ایجاد و سرانه توزیع شد.", deptName), ui.Window)
 	}, ui.Window)
 }
 func (ui *MainUI) onAdminImportExcel() {
+	if !ui.authorize(auth.CapImport, "") {
+		return
+	}
 	fileOpenDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, errDialog error) {
 		if errDialog != nil {
 			dialog.ShowError(errDialog, ui.Window)
@@ -820,24 +1305,24 @@ func (ui *MainUI) onAdminImportExcel() {
 		go func() {
 			defer progress.Hide()
 
-			fileTotalHours, fileProdDays, fileMonthF3, errReadBase := excel.ReadBasicDataFromExcel(filePath)
+			layout := core.DefaultLayoutProfile()
+			report := &excel.Report{}
+			fileTotalHours, fileProdDays, filePeriodF3, errReadBase := excel.ReadBasicDataFromExcel(filePath, layout, report)
 			if errReadBase != nil {
 				dialog.ShowInformation("هشدار خواندن فایل", fmt.Sprintf("خطا در خواندن اطلاعات پایه (F1,F2,F3) از فایل اکسل: %v\nبا مقادیر پیش‌فرض برای اولین واحد ادامه داده می‌شود.", errReadBase), ui.Window)
 				fileTotalHours = 0
 				fileProdDays = 0
-				fileMonthF3 = ""
+				filePeriodF3 = core.Period{}
 			}
-			finalFileMainMonth := fileMonthF3
-			if finalFileMainMonth == "" {
-				finalFileMainMonth = core.GetCurrentPersianMonthName()
-				if fileMonthF3 != "" {
-					dialog.ShowInformation("هشدار ماه فایل", fmt.Sprintf("مقدار ماه در سلول %s فایل اکسل ('%s') نامعتبر است. از ماه جاری سیستم (%s) برای اولین واحد استفاده خواهد شد.", core.MonthCell, fileMonthF3, finalFileMainMonth), ui.Window)
-				}
+			budgets, hasBudgetSheet, errBudget := excel.ReadBudgetSheet(filePath, report)
+			if errBudget != nil {
+				dialog.ShowError(fmt.Errorf("خطا در خواندن شیت سرانه واحدها: %w", errBudget), ui.Window)
+				return
 			}
-			importedDeptShiftsSuccess := []string{}
 			skippedDeptsMessages := []string{}
+			budgetNotes := []string{}
 
-			uniqueDeptsInFile, err := excel.GetUniqueDepartmentsFromFile(filePath)
+			uniqueDeptsInFile, err := excel.GetUniqueDepartmentsFromFile(filePath, layout, report)
 			if err != nil {
 				dialog.ShowError(fmt.Errorf("خطا در خواندن لیست واحدها از فایل اکسل: %w", err), ui.Window)
 				return
@@ -846,6 +1331,12 @@ func (ui *MainUI) onAdminImportExcel() {
 				dialog.ShowInformation("بدون داده", "هیچ نام واحدی در ستون اول فایل اکسل یافت نشد.", ui.Window)
 				return
 			}
+			// ابتدا همه واحدها خوانده می‌شوند تا گزارش بررسی فایل پیش از اعمال هر تغییری نمایش داده شود.
+			type pendingImport struct {
+				deptShift string
+				imported  core.ImportedDepartment
+			}
+			var pending []pendingImport
 			processedFirstDeptInFile := false
 			for _, deptShiftToImport := range uniqueDeptsInFile {
 				isManageable := false
@@ -859,7 +1350,7 @@ func (ui *MainUI) onAdminImportExcel() {
 					skippedDeptsMessages = append(skippedDeptsMessages, fmt.Sprintf("%s (واحد تعریف نشده در برنامه)", deptShiftToImport))
 					continue
 				}
-				employees, err := excel.ReadEmployeesFromExcel(filePath, deptShiftToImport)
+				employees, err := excel.ReadEmployeesFromExcel(filePath, deptShiftToImport, layout, report)
 				if err != nil {
 					skippedDeptsMessages = append(skippedDeptsMessages, fmt.Sprintf("%s (خطا در خواندن پرسنل: %v)", deptShiftToImport, err))
 					continue
@@ -868,52 +1359,95 @@ func (ui *MainUI) onAdminImportExcel() {
 					skippedDeptsMessages = append(skippedDeptsMessages, fmt.Sprintf("%s (بدون پرسنل در فایل)", deptShiftToImport))
 					continue
 				}
-				currentDeptTotalHours := 0
-				currentDeptProdDays := 0
-				currentDeptMonth := core.GetCurrentPersianMonthName()
-				if !processedFirstDeptInFile {
-					currentDeptTotalHours = fileTotalHours
-					currentDeptProdDays = fileProdDays
-					currentDeptMonth = finalFileMainMonth
+				imported := core.ImportedDepartment{Employees: employees}
+				if hasBudgetSheet {
+					// با وجود شیت سرانه، هر واحد سرانه، روزهای تولید و ماه خود را دارد؛ ماه خالی از F3 گرفته می‌شود.
+					budget, ok := budgets[deptShiftToImport]
+					if !ok {
+						budgetNotes = append(budgetNotes, fmt.Sprintf("%s (ردیفی در شیت سرانه ندارد؛ سرانه صفر در نظر گرفته شد)", deptShiftToImport))
+					}
+					imported.TotalHours = budget.TotalHours
+					imported.ProductionDays = budget.ProductionDays
+					imported.Period = budget.Period
+					if imported.Period.IsZero() {
+						imported.Period = filePeriodF3
+					}
+				} else if !processedFirstDeptInFile {
+					imported.TotalHours = fileTotalHours
+					imported.ProductionDays = fileProdDays
+					imported.Period = filePeriodF3
 					processedFirstDeptInFile = true
 				}
+				pending = append(pending, pendingImport{deptShift: deptShiftToImport, imported: imported})
+			}
+			if hasBudgetSheet {
+				for deptShift := range budgets {
+					found := false
+					for _, dept := range uniqueDeptsInFile {
+						if dept == deptShift {
+							found = true
+							break
+						}
+					}
+					if !found {
+						budgetNotes = append(budgetNotes, fmt.Sprintf("%s (در شیت سرانه هست اما پرسنلی در فایل ندارد)", deptShift))
+					}
+				}
+			}
 
-				deptData, exists := core.AllDepartmentsData[deptShiftToImport]
-				if !exists {
-					deptData = &core.DepartmentData{DepartmentShiftName: deptShiftToImport}
-					core.AllDepartmentsData[deptShiftToImport] = deptData
+			applyImports := func() {
+				if !ui.authorize(auth.CapImport, "") {
+					return
 				}
-				deptData.TotalHours = currentDeptTotalHours
-				deptData.ProductionDays = currentDeptProdDays
-				deptData.MonthName = currentDeptMonth
-				deptData.Employees = employees
-				for i := range deptData.Employees {
-					deptData.Employees[i].MonthType = currentDeptMonth
+				ui.commitPendingEdit()
+				importedDeptShiftsSuccess := []string{}
+				for _, p := range pending {
+					deptData, exists := core.AllDepartmentsData[p.deptShift]
+					if !exists {
+						deptData = &core.DepartmentData{DepartmentShiftName: p.deptShift}
+						core.AllDepartmentsData[p.deptShift] = deptData
+					}
+					core.ApplyImport(deptData, p.imported)
+					ui.saveDepartment(deptData, audit.SourceAdminImport)
+					importedDeptShiftsSuccess = append(importedDeptShiftsSuccess, p.deptShift)
 				}
-				importedDeptShiftsSuccess = append(importedDeptShiftsSuccess, deptShiftToImport)
-			}
 
-			if len(importedDeptShiftsSuccess) > 0 {
-				currentSelectedInCombo := ui.deptComboBox.Selected
-				needsUIFullRefreshForCurrent := false
-				for _, imported := range importedDeptShiftsSuccess {
-					if imported == currentSelectedInCombo {
-						needsUIFullRefreshForCurrent = true
-						break
+				if len(importedDeptShiftsSuccess) > 0 {
+					currentSelectedInCombo := ui.departmentShiftForLabel(ui.deptComboBox.Selected)
+					needsUIFullRefreshForCurrent := false
+					for _, imported := range importedDeptShiftsSuccess {
+						if imported == currentSelectedInCombo {
+							needsUIFullRefreshForCurrent = true
+							break
+						}
+					}
+					if needsUIFullRefreshForCurrent {
+						ui.refreshUIForCurrentDepartment()
 					}
 				}
-				if needsUIFullRefreshForCurrent {
-					ui.refreshUIForCurrentDepartment()
+				if len(budgetNotes) > 0 {
+					sort.Strings(budgetNotes)
+					dialog.ShowInformation("هشدار شیت سرانه", strings.Join(budgetNotes, "\n"), ui.Window)
+				}
+				if len(skippedDeptsMessages) > 0 {
+					dialog.ShowInformation("واحدهای رد شده", strings.Join(skippedDeptsMessages, "\n"), ui.Window)
 				}
 			}
-			if len(skippedDeptsMessages) > 0 {
-				dialog.ShowInformation("واحدهای رد شده", strings.Join(skippedDeptsMessages, "\n"), ui.Window)
-			}
+			fyne.Do(func() {
+				if report.HasIssues() {
+					ShowValidationReportDialog(ui.Window, "گزارش بررسی فایل اکسل", report, applyImports)
+					return
+				}
+				applyImports()
+			})
 		}()
 	}, ui.Window)
 	fileOpenDialog.Show()
 }
 func (ui *MainUI) onManageCloudLinks() {
+	if !ui.authorize(auth.CapManageLinks, "") {
+		return
+	}
 	linkManagerDialog := CreateCloudLinkManagerDialog(ui.App, ui.Window, func(changed bool) {
 		if changed {
 			fmt.Println("لینک‌های ابری از دیالوگ تغییر کردند.")
@@ -922,6 +1456,23 @@ func (ui *MainUI) onManageCloudLinks() {
 	})
 	linkManagerDialog.Show()
 }
+func (ui *MainUI) onChangePassword() {
+	ShowChangePasswordDialog(ui.Window, ui.User.Username, false, func(string) {
+		dialog.ShowInformation("تغییر رمز عبور", "رمز عبور شما با موفقیت تغییر کرد.", ui.Window)
+	}, nil)
+}
+func (ui *MainUI) onManageUsers() {
+	if !ui.authorize(auth.CapManageUsers, "") {
+		return
+	}
+	ShowUserManagerDialog(ui.Window, ui.User.Username)
+}
+func (ui *MainUI) onManageOrgStructure() {
+	if !ui.authorize(auth.CapManageOrg, "") {
+		return
+	}
+	ShowOrgStructureDialog(ui.Window)
+}
 func parseURL(urlStr string) *url.URL {
 	u, err := url.Parse(urlStr)
 	if err != nil {
@@ -0,0 +1,351 @@
+package gui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"overtime_go/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+type orgStructureDialog struct {
+	parentWindow fyne.Window
+	structure    core.OrgStructure // نسخه در حال ویرایش؛ فقط با "ذخیره" اعمال می‌شود
+
+	deptTable     *widget.Table
+	groupTable    *widget.Table
+	selectedDept  int
+	selectedGroup int
+}
+
+// ShowOrgStructureDialog ویرایشگر ساختار سازمانی (واحدها، شیفت‌ها، نام‌های نمایشی و گروه‌های ویژه) را نمایش می‌دهد.
+// تغییرات پس از اعتبارسنجی در org_structure.json کنار برنامه ذخیره می‌شوند.
+func ShowOrgStructureDialog(parent fyne.Window) {
+	m := &orgStructureDialog{
+		parentWindow:  parent,
+		structure:     core.CurrentOrgStructure(),
+		selectedDept:  -1,
+		selectedGroup: -1,
+	}
+
+	m.deptTable = widget.NewTable(
+		func() (int, int) { return len(m.structure.Departments) + 1, 3 },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		m.updateDeptCell,
+	)
+	m.deptTable.SetColumnWidth(0, 220)
+	m.deptTable.SetColumnWidth(1, 220)
+	m.deptTable.SetColumnWidth(2, 200)
+	m.deptTable.OnSelected = func(id widget.TableCellID) {
+		if id.Row == 0 {
+			m.deptTable.UnselectAll()
+			return
+		}
+		m.selectedDept = id.Row - 1
+	}
+
+	m.groupTable = widget.NewTable(
+		func() (int, int) { return len(m.structure.Groups) + 1, 2 },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		m.updateGroupCell,
+	)
+	m.groupTable.SetColumnWidth(0, 220)
+	m.groupTable.SetColumnWidth(1, 420)
+	m.groupTable.OnSelected = func(id widget.TableCellID) {
+		if id.Row == 0 {
+			m.groupTable.UnselectAll()
+			return
+		}
+		m.selectedGroup = id.Row - 1
+	}
+
+	deptButtons := container.NewHBox(
+		widget.NewButtonWithIcon("واحد جدید", theme.ContentAddIcon(), m.onAddDepartment),
+		widget.NewButtonWithIcon("ویرایش", theme.DocumentCreateIcon(), m.onEditDepartment),
+		widget.NewButtonWithIcon("حذف", theme.DeleteIcon(), m.onDeleteDepartment),
+	)
+	groupButtons := container.NewHBox(
+		widget.NewButtonWithIcon("گروه جدید", theme.ContentAddIcon(), m.onAddGroup),
+		widget.NewButtonWithIcon("ویرایش", theme.DocumentCreateIcon(), m.onEditGroup),
+		widget.NewButtonWithIcon("حذف", theme.DeleteIcon(), m.onDeleteGroup),
+	)
+	tabs := container.NewAppTabs(
+		container.NewTabItem("واحدها و شیفت‌ها", container.NewBorder(deptButtons, nil, nil, nil, m.deptTable)),
+		container.NewTabItem("گروه‌های ویژه", container.NewBorder(groupButtons, nil, nil, nil, m.groupTable)),
+	)
+
+	defaultsButton := widget.NewButtonWithIcon("بازگردانی پیش‌فرض", theme.ViewRefreshIcon(), func() {
+		dialog.ShowConfirm("بازگردانی پیش‌فرض", "ساختار سازمانی پیش‌فرض برنامه جایگزین تغییرات فعلی ویرایشگر شود؟", func(confirm bool) {
+			if confirm {
+				m.structure = core.DefaultOrgStructure()
+				m.refresh()
+			}
+		}, parent)
+	})
+	helpLabel := widget.NewLabel(`- کلید هر واحد-شیفت (مانند "انبار - شیفتی") مبنای داده‌های ذخیره شده، لینک‌ها و دسترسی کاربران است؛ برای تغییر نام بدون از دست رفتن این موارد، از "نام نمایشی" استفاده کنید.
+- تغییرات در فایل org_structure.json (کنار فایل اجرایی برنامه) ذخیره می‌شوند و فهرست واحدهای صفحه اصلی پس از ورود مجدد به‌روز می‌شود.`)
+	helpLabel.Wrapping = fyne.TextWrapWord
+
+	content := container.NewBorder(nil, container.NewVBox(helpLabel, container.NewHBox(defaultsButton)), nil, nil, tabs)
+	d := dialog.NewCustomConfirm("ساختار سازمانی", "ذخیره", "انصراف", trackActivity(content), func(confirm bool) {
+		if !confirm {
+			return
+		}
+		if err := core.SaveOrgStructure(m.structure); err != nil {
+			dialog.ShowError(fmt.Errorf("خطا در ذخیره ساختار سازمانی: %w", err), parent)
+			return
+		}
+		dialog.ShowInformation("ساختار سازمانی", "ساختار سازمانی ذخیره شد. برای مشاهده تغییرات در فهرست واحدها، از حساب خارج و دوباره وارد شوید.", parent)
+	}, parent)
+	d.Resize(fyne.NewSize(750, 550))
+	d.Show()
+}
+
+func (m *orgStructureDialog) refresh() {
+	m.selectedDept = -1
+	m.selectedGroup = -1
+	m.deptTable.UnselectAll()
+	m.groupTable.UnselectAll()
+	m.deptTable.Refresh()
+	m.groupTable.Refresh()
+}
+
+func (m *orgStructureDialog) updateDeptCell(id widget.TableCellID, template fyne.CanvasObject) {
+	label := template.(*widget.Label)
+	if id.Row == 0 {
+		headers := []string{"نام واحد", "نام نمایشی", "شیفت‌ها"}
+		label.SetText(headers[id.Col])
+		label.TextStyle = fyne.TextStyle{Bold: true}
+		label.Refresh()
+		return
+	}
+	label.TextStyle = fyne.TextStyle{}
+	if id.Row-1 >= len(m.structure.Departments) {
+		label.SetText("")
+		return
+	}
+	dept := m.structure.Departments[id.Row-1]
+	switch id.Col {
+	case 0:
+		label.SetText(dept.Name)
+	case 1:
+		label.SetText(dept.DisplayName)
+	case 2:
+		label.SetText(strings.Join(dept.Shifts, "، "))
+	}
+}
+
+func (m *orgStructureDialog) updateGroupCell(id widget.TableCellID, template fyne.CanvasObject) {
+	label := template.(*widget.Label)
+	if id.Row == 0 {
+		headers := []string{"نام گروه", "واحد-شیفت‌ها"}
+		label.SetText(headers[id.Col])
+		label.TextStyle = fyne.TextStyle{Bold: true}
+		label.Refresh()
+		return
+	}
+	label.TextStyle = fyne.TextStyle{}
+	if id.Row-1 >= len(m.structure.Groups) {
+		label.SetText("")
+		return
+	}
+	group := m.structure.Groups[id.Row-1]
+	switch id.Col {
+	case 0:
+		label.SetText(group.Name)
+	case 1:
+		label.SetText(fmt.Sprintf("%d واحد-شیفت: %s", len(group.DepartmentShifts), strings.Join(group.DepartmentShifts, "، ")))
+	}
+}
+
+// parseShifts فهرست شیفت‌های جدا شده با کاما (یا ویرگول فارسی) را برمی‌گرداند.
+func parseShifts(text string) []string {
+	var shifts []string
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == '،' }) {
+		if shift := strings.TrimSpace(part); shift != "" {
+			shifts = append(shifts, shift)
+		}
+	}
+	return shifts
+}
+
+// showDepartmentForm فرم ویرایش یک واحد را نمایش می‌دهد و واحد ویرایش شده را به onSubmit می‌دهد.
+func (m *orgStructureDialog) showDepartmentForm(title string, dept core.OrgDepartment, onSubmit func(core.OrgDepartment)) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(dept.Name)
+	displayEntry := widget.NewEntry()
+	displayEntry.SetText(dept.DisplayName)
+	displayEntry.SetPlaceHolder("اختیاری")
+	shiftsEntry := widget.NewEntry()
+	shiftsEntry.SetText(strings.Join(dept.Shifts, "، "))
+	shiftsEntry.SetPlaceHolder("مثلا: شیفتی، ثابت")
+
+	dialog.ShowForm(title, "تأیید", "انصراف", []*widget.FormItem{
+		widget.NewFormItem("نام واحد:", nameEntry),
+		widget.NewFormItem("نام نمایشی:", displayEntry),
+		widget.NewFormItem("شیفت‌ها:", shiftsEntry),
+	}, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		onSubmit(core.OrgDepartment{
+			Name:        strings.TrimSpace(nameEntry.Text),
+			DisplayName: strings.TrimSpace(displayEntry.Text),
+			Shifts:      parseShifts(shiftsEntry.Text),
+		})
+	}, m.parentWindow)
+}
+
+func (m *orgStructureDialog) onAddDepartment() {
+	m.showDepartmentForm("واحد جدید", core.OrgDepartment{}, func(dept core.OrgDepartment) {
+		m.structure.Departments = append(m.structure.Departments, dept)
+		m.refresh()
+	})
+}
+
+func (m *orgStructureDialog) selectedDepartment() (int, bool) {
+	if m.selectedDept < 0 || m.selectedDept >= len(m.structure.Departments) {
+		dialog.ShowInformation("انتخاب واحد", "ابتدا یک واحد را از جدول انتخاب کنید.", m.parentWindow)
+		return -1, false
+	}
+	return m.selectedDept, true
+}
+
+func (m *orgStructureDialog) onEditDepartment() {
+	idx, ok := m.selectedDepartment()
+	if !ok {
+		return
+	}
+	old := m.structure.Departments[idx]
+	m.showDepartmentForm("ویرایش واحد "+old.Name, old, func(dept core.OrgDepartment) {
+		m.structure.Departments[idx] = dept
+		// اعضای گروه‌ها با نام جدید واحد به‌روز و شیفت‌های حذف شده از گروه‌ها برداشته می‌شوند
+		renamed := make(map[string]string)
+		for _, shift := range old.Shifts {
+			renamed[core.DepartmentShiftKey(old.Name, shift)] = ""
+		}
+		for _, shift := range dept.Shifts {
+			if _, existed := renamed[core.DepartmentShiftKey(old.Name, shift)]; existed {
+				renamed[core.DepartmentShiftKey(old.Name, shift)] = core.DepartmentShiftKey(dept.Name, shift)
+			}
+		}
+		m.replaceGroupMembers(renamed)
+		m.refresh()
+	})
+}
+
+func (m *orgStructureDialog) onDeleteDepartment() {
+	idx, ok := m.selectedDepartment()
+	if !ok {
+		return
+	}
+	dept := m.structure.Departments[idx]
+	dialog.ShowConfirm("حذف واحد", fmt.Sprintf("واحد '%s' و شیفت‌های آن از ساختار سازمانی حذف شود؟ داده‌های ذخیره شده آن حذف نمی‌شوند.", dept.Name), func(confirm bool) {
+		if !confirm {
+			return
+		}
+		removed := make(map[string]string)
+		for _, shift := range dept.Shifts {
+			removed[core.DepartmentShiftKey(dept.Name, shift)] = ""
+		}
+		m.structure.Departments = append(m.structure.Departments[:idx], m.structure.Departments[idx+1:]...)
+		m.replaceGroupMembers(removed)
+		m.refresh()
+	}, m.parentWindow)
+}
+
+// replaceGroupMembers اعضای گروه‌ها را بر اساس replacements جایگزین می‌کند؛ مقدار خالی یعنی حذف عضو.
+func (m *orgStructureDialog) replaceGroupMembers(replacements map[string]string) {
+	for i, group := range m.structure.Groups {
+		var members []string
+		for _, member := range group.DepartmentShifts {
+			replacement, ok := replacements[member]
+			if !ok {
+				members = append(members, member)
+			} else if replacement != "" {
+				members = append(members, replacement)
+			}
+		}
+		m.structure.Groups[i].DepartmentShifts = members
+	}
+}
+
+// departmentShiftKeys همه واحد-شیفت‌های ساختار در حال ویرایش را به ترتیب الفبا برمی‌گرداند.
+func (m *orgStructureDialog) departmentShiftKeys() []string {
+	var keys []string
+	for _, dept := range m.structure.Departments {
+		for _, shift := range dept.Shifts {
+			keys = append(keys, core.DepartmentShiftKey(dept.Name, shift))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m *orgStructureDialog) showGroupForm(title string, group core.OrgGroup, onSubmit func(core.OrgGroup)) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(group.Name)
+	membersCheck := widget.NewCheckGroup(m.departmentShiftKeys(), nil)
+	membersCheck.SetSelected(group.DepartmentShifts)
+
+	content := container.NewBorder(
+		widget.NewForm(widget.NewFormItem("نام گروه:", nameEntry)),
+		nil, nil, nil,
+		container.NewVScroll(membersCheck),
+	)
+	d := dialog.NewCustomConfirm(title, "تأیید", "انصراف", content, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		onSubmit(core.OrgGroup{Name: strings.TrimSpace(nameEntry.Text), DepartmentShifts: membersCheck.Selected})
+	}, m.parentWindow)
+	d.Resize(fyne.NewSize(500, 500))
+	d.Show()
+}
+
+func (m *orgStructureDialog) onAddGroup() {
+	m.showGroupForm("گروه جدید", core.OrgGroup{}, func(group core.OrgGroup) {
+		m.structure.Groups = append(m.structure.Groups, group)
+		m.refresh()
+	})
+}
+
+func (m *orgStructureDialog) selectedGroupIndex() (int, bool) {
+	if m.selectedGroup < 0 || m.selectedGroup >= len(m.structure.Groups) {
+		dialog.ShowInformation("انتخاب گروه", "ابتدا یک گروه را از جدول انتخاب کنید.", m.parentWindow)
+		return -1, false
+	}
+	return m.selectedGroup, true
+}
+
+func (m *orgStructureDialog) onEditGroup() {
+	idx, ok := m.selectedGroupIndex()
+	if !ok {
+		return
+	}
+	m.showGroupForm("ویرایش گروه "+m.structure.Groups[idx].Name, m.structure.Groups[idx], func(group core.OrgGroup) {
+		m.structure.Groups[idx] = group
+		m.refresh()
+	})
+}
+
+func (m *orgStructureDialog) onDeleteGroup() {
+	idx, ok := m.selectedGroupIndex()
+	if !ok {
+		return
+	}
+	group := m.structure.Groups[idx]
+	dialog.ShowConfirm("حذف گروه", fmt.Sprintf("گروه '%s' حذف شود؟ کاربرانی که دپارتمان آن‌ها این گروه است دسترسی خود را از دست می‌دهند.", group.Name), func(confirm bool) {
+		if !confirm {
+			return
+		}
+		m.structure.Groups = append(m.structure.Groups[:idx], m.structure.Groups[idx+1:]...)
+		m.refresh()
+	}, m.parentWindow)
+}
@@ -0,0 +1,60 @@
+package gui
+
+import (
+	"fmt"
+
+	"overtime_go/auth"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ShowChangePasswordDialog فرم تغییر رمز عبور کاربر را نمایش می‌دهد. در حالت forced (الزام تغییر رمز
+// پس از بازنشانی توسط مدیر) انصراف به معنای لغو ورود است و onCancel فراخوانی می‌شود.
+func ShowChangePasswordDialog(win fyne.Window, username string, forced bool, onChanged func(newPassword string), onCancel func()) {
+	currentEntry := widget.NewPasswordEntry()
+	newEntry := widget.NewPasswordEntry()
+	confirmEntry := widget.NewPasswordEntry()
+	policyLabel := widget.NewLabel(auth.PasswordPolicyDescription)
+	policyLabel.Wrapping = fyne.TextWrapWord
+
+	title := "تغییر رمز عبور"
+	if forced {
+		title = "رمز عبور شما باید تغییر کند"
+	}
+
+	var retry func()
+	showForm := func() {
+		dialog.ShowForm(title, "تغییر رمز", "انصراف", []*widget.FormItem{
+			widget.NewFormItem("رمز عبور فعلی:", currentEntry),
+			widget.NewFormItem("رمز عبور جدید:", newEntry),
+			widget.NewFormItem("تکرار رمز جدید:", confirmEntry),
+			widget.NewFormItem("", policyLabel),
+		}, func(confirm bool) {
+			if !confirm {
+				if onCancel != nil {
+					onCancel()
+				}
+				return
+			}
+			var err error
+			if newEntry.Text != confirmEntry.Text {
+				err = fmt.Errorf("رمز عبور جدید و تکرار آن یکسان نیستند")
+			} else {
+				err = auth.ChangePassword(username, currentEntry.Text, newEntry.Text)
+			}
+			if err != nil {
+				errDialog := dialog.NewError(err, win)
+				errDialog.SetOnClosed(retry)
+				errDialog.Show()
+				return
+			}
+			if onChanged != nil {
+				onChanged(newEntry.Text)
+			}
+		}, win)
+	}
+	retry = showForm
+	showForm()
+}
@@ -0,0 +1,86 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+
+	"overtime_go/core"
+	"overtime_go/history"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+var departmentsSummaryHeaders = []string{"واحد", "دوره", "تعداد پرسنل", "سرانه", "تخصیص یافته", "اختلاف", "میانگین هر نفر", "بیشترین"}
+
+// ShowDepartmentsSummaryDialog خلاصه تخصیص جاری (آخرین داده ذخیره شده) همه واحدهای accessibleDepts
+// را به همراه جمع کل به صورت فقط خواندنی نمایش می‌دهد.
+func ShowDepartmentsSummaryDialog(parent fyne.Window, accessibleDepts []string) {
+	var summaries []history.DepartmentSummary
+	for _, deptShift := range accessibleDepts {
+		data, ok := core.AllDepartmentsData[deptShift]
+		if !ok || data == nil {
+			summaries = append(summaries, history.DepartmentSummary{DepartmentShift: deptShift})
+			continue
+		}
+		summaries = append(summaries, history.SummarizeDepartment(*data))
+	}
+	total := history.AggregateSummaries(summaries)
+	total.DepartmentShift = "جمع کل"
+
+	rows := append(summaries, total)
+	table := widget.NewTable(
+		func() (int, int) { return len(rows) + 1, len(departmentsSummaryHeaders) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, template fyne.CanvasObject) {
+			label := template.(*widget.Label)
+			if id.Row == 0 {
+				label.SetText(departmentsSummaryHeaders[id.Col])
+				label.TextStyle = fyne.TextStyle{Bold: true}
+				label.Refresh()
+				return
+			}
+			s := rows[id.Row-1]
+			label.TextStyle = fyne.TextStyle{Bold: id.Row == len(rows)}
+			switch id.Col {
+			case 0:
+				label.SetText(s.DepartmentShift)
+			case 1:
+				label.SetText(s.Period.String())
+			case 2:
+				label.SetText(strconv.Itoa(s.EmployeeCount))
+			case 3:
+				label.SetText(strconv.Itoa(s.Budget))
+			case 4:
+				label.SetText(strconv.Itoa(s.Allocated))
+			case 5:
+				label.SetText(strconv.Itoa(s.Allocated - s.Budget))
+			case 6:
+				label.SetText(fmt.Sprintf("%.1f", s.AverageHours()))
+			case 7:
+				label.SetText(strconv.Itoa(s.MaxHours))
+			}
+			label.Refresh()
+		},
+	)
+	table.SetColumnWidth(0, 260)
+	table.SetColumnWidth(1, 110)
+	for col := 2; col < len(departmentsSummaryHeaders); col++ {
+		table.SetColumnWidth(col, 100)
+	}
+
+	mismatched := 0
+	for _, s := range summaries {
+		if s.EmployeeCount > 0 && s.Allocated != s.Budget {
+			mismatched++
+		}
+	}
+	infoLabel := widget.NewLabel(fmt.Sprintf("%d واحد؛ در %d واحد مجموع ساعات تخصیص یافته با سرانه برابر نیست. داده‌ها فقط قابل مشاهده هستند.", len(summaries), mismatched))
+	infoLabel.Wrapping = fyne.TextWrapWord
+
+	d := dialog.NewCustom("خلاصه تخصیص همه واحدها", "بستن", trackActivity(container.NewBorder(infoLabel, nil, nil, nil, table)), parent)
+	d.Resize(fyne.NewSize(1000, 600))
+	d.Show()
+}
@@ -0,0 +1,89 @@
+package gui
+
+import (
+	"overtime_go/audit"
+	"overtime_go/auth"
+	"overtime_go/core"
+
+	"fyne.io/fyne/v2"
+)
+
+func (ui *MainUI) undoStackFor(deptShift string) *core.UndoStack {
+	stack, ok := ui.undoStacks[deptShift]
+	if !ok {
+		stack = core.NewUndoStack(core.DefaultUndoLimit)
+		ui.undoStacks[deptShift] = stack
+	}
+	return stack
+}
+
+// setupUndoShortcuts منوی "ویرایش" را با میانبرهای Ctrl+Z و Ctrl+Y می‌سازد. میانبرهای منوی اصلی پیش از
+// ویجت دارای فوکوس (مانند خانه ساعت جدول) بررسی می‌شوند، پس بازگشت در همه حالت‌ها روی کل جدول اعمال می‌شود.
+func (ui *MainUI) setupUndoShortcuts() {
+	if !auth.Can(ui.User, auth.CapEditAllocation) {
+		ui.Window.SetMainMenu(nil)
+		return
+	}
+	undoItem := fyne.NewMenuItem("بازگشت", ui.onUndo)
+	undoItem.Shortcut = &fyne.ShortcutUndo{}
+	redoItem := fyne.NewMenuItem("انجام مجدد", ui.onRedo)
+	redoItem.Shortcut = &fyne.ShortcutRedo{}
+	ui.Window.SetMainMenu(fyne.NewMainMenu(fyne.NewMenu("ویرایش", undoItem, redoItem)))
+}
+
+func (ui *MainUI) onUndo() {
+	ui.applyUndoRedo(audit.SourceUndo)
+}
+
+func (ui *MainUI) onRedo() {
+	ui.applyUndoRedo(audit.SourceRedo)
+}
+
+// applyUndoRedo نسخه قبلی (یا بعدی) واحد جاری را جایگزین داده فعلی می‌کند، جدول را به‌روز و تغییر را ذخیره می‌کند.
+func (ui *MainUI) applyUndoRedo(source audit.Source) {
+	data := ui.currentDepartmentData
+	if data == nil || !auth.Can(ui.User, auth.CapEditAllocation) {
+		return
+	}
+	ui.idle.Touch()
+	// ویرایش در حال تایپ ابتدا به عنوان یک گام ثبت می‌شود تا بازگشت به مقدار پیش از آن برگردد.
+	ui.commitPendingEdit()
+	stack := ui.undoStackFor(data.DepartmentShiftName)
+	var snapshot core.DepartmentData
+	var ok bool
+	if source == audit.SourceUndo {
+		snapshot, ok = stack.Undo(data.Clone())
+	} else {
+		snapshot, ok = stack.Redo(data.Clone())
+	}
+	if !ok {
+		return
+	}
+	*data = snapshot.Clone()
+	ui.lastDiagnostics = nil
+	ui.refreshUIForCurrentDepartment()
+	ui.saveDepartment(data, source)
+	ui.updateUndoRedoState()
+}
+
+// updateUndoRedoState دکمه‌های بازگشت و انجام مجدد را بر اساس پشته واحد جاری فعال یا غیرفعال می‌کند.
+func (ui *MainUI) updateUndoRedoState() {
+	if ui.undoButton == nil || ui.redoButton == nil {
+		return
+	}
+	canUndo, canRedo := false, false
+	if ui.currentDepartmentData != nil && auth.Can(ui.User, auth.CapEditAllocation) {
+		stack := ui.undoStackFor(ui.currentDepartmentData.DepartmentShiftName)
+		canUndo, canRedo = stack.CanUndo(), stack.CanRedo()
+	}
+	if canUndo {
+		ui.undoButton.Enable()
+	} else {
+		ui.undoButton.Disable()
+	}
+	if canRedo {
+		ui.redoButton.Enable()
+	} else {
+		ui.redoButton.Disable()
+	}
+}
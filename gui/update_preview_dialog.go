@@ -0,0 +1,83 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"overtime_go/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ShowUpdatePreviewDialog تفاوت داده فعلی واحد با داده دریافت شده از سرور را نمایش می‌دهد و پس از تأیید،
+// onApply را با انتخاب کاربر درباره حفظ ساعات قفل شده فراخوانی می‌کند.
+func ShowUpdatePreviewDialog(parent fyne.Window, deptShift string, preview core.UpdatePreview, onApply func(keepLocks bool)) {
+	var lines []string
+	if preview.OldTotalHours != preview.NewTotalHours {
+		lines = append(lines, fmt.Sprintf("سرانه: %d ← %d", preview.OldTotalHours, preview.NewTotalHours))
+	}
+	if preview.OldProductionDays != preview.NewProductionDays {
+		lines = append(lines, fmt.Sprintf("روزهای تولید: %d ← %d", preview.OldProductionDays, preview.NewProductionDays))
+	}
+	if preview.OldPeriod != preview.NewPeriod {
+		oldPeriod := preview.OldPeriod.String()
+		if oldPeriod == "" {
+			oldPeriod = "نامشخص"
+		}
+		lines = append(lines, fmt.Sprintf("ماه تخصیص: %s ← %s", oldPeriod, preview.NewPeriod))
+	}
+	if len(preview.Added) > 0 {
+		lines = append(lines, fmt.Sprintf("\nپرسنل جدید (%d نفر):", len(preview.Added)))
+		for _, emp := range preview.Added {
+			lines = append(lines, fmt.Sprintf("  + %s (%s)", emp.Name, emp.ID))
+		}
+	}
+	if len(preview.Removed) > 0 {
+		lines = append(lines, fmt.Sprintf("\nپرسنل حذف شده (%d نفر):", len(preview.Removed)))
+		for _, emp := range preview.Removed {
+			lines = append(lines, fmt.Sprintf("  - %s (%s) - %d ساعت", emp.Name, emp.ID, emp.Hours))
+		}
+	}
+	if len(preview.Renamed) > 0 {
+		lines = append(lines, fmt.Sprintf("\nتغییر نام (%d نفر):", len(preview.Renamed)))
+		for _, r := range preview.Renamed {
+			lines = append(lines, fmt.Sprintf("  %s: %s ← %s", r.ID, r.OldName, r.NewName))
+		}
+	}
+	if len(preview.IDChanged) > 0 {
+		lines = append(lines, fmt.Sprintf("\nتغییر کد پرسنلی (%d نفر):", len(preview.IDChanged)))
+		for _, c := range preview.IDChanged {
+			lines = append(lines, fmt.Sprintf("  %s: %s ← %s", c.Name, c.OldID, c.NewID))
+		}
+	}
+	if !preview.HasChanges() {
+		lines = append(lines, "لیست پرسنل، سرانه، روزهای تولید و ماه تخصیص تغییری نکرده است.")
+	}
+
+	details := widget.NewLabel(strings.Join(lines, "\n"))
+	details.Wrapping = fyne.TextWrapWord
+
+	keepLocksCheck := widget.NewCheck(fmt.Sprintf("حفظ ساعات قفل شده پرسنل موجود (%d نفر)", preview.KeepableLocks), nil)
+	if preview.KeepableLocks > 0 {
+		keepLocksCheck.SetChecked(true)
+	} else {
+		keepLocksCheck.Disable()
+	}
+
+	content := container.NewBorder(
+		widget.NewLabel(fmt.Sprintf("تغییرات اطلاعات واحد '%s' پس از به‌روزرسانی از سرور:", deptShift)),
+		keepLocksCheck,
+		nil, nil,
+		container.NewVScroll(details),
+	)
+	d := dialog.NewCustomConfirm("پیش‌نمایش به‌روزرسانی", "اعمال تغییرات", "انصراف", trackActivity(content), func(confirm bool) {
+		if confirm {
+			onApply(keepLocksCheck.Checked)
+		}
+	}, parent)
+	d.Resize(fyne.NewSize(600, 500))
+	d.Show()
+}
@@ -0,0 +1,319 @@
+package gui
+
+import (
+	"fmt"
+
+	"overtime_go/auth"
+	"overtime_go/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+func roleByDisplayName(name string) string {
+	for _, role := range auth.RoleNames() {
+		if auth.RoleDisplayName(role) == name {
+			return role
+		}
+	}
+	return name
+}
+
+type userManagerDialog struct {
+	dialog       dialog.Dialog
+	parentWindow fyne.Window
+	currentUser  string
+	usersTable   *widget.Table
+
+	users       []core.User
+	selectedRow int
+}
+
+// ShowUserManagerDialog دیالوگ مدیریت کاربران (ایجاد، ویرایش، بازنشانی رمز، غیرفعال‌سازی و حذف) را نمایش می‌دهد.
+func ShowUserManagerDialog(parent fyne.Window, currentUser string) {
+	m := &userManagerDialog{
+		parentWindow: parent,
+		currentUser:  currentUser,
+		selectedRow:  -1,
+	}
+
+	m.usersTable = widget.NewTable(
+		func() (int, int) { return len(m.users) + 1, 4 },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		m.updateCell,
+	)
+	m.usersTable.SetColumnWidth(0, 150)
+	m.usersTable.SetColumnWidth(1, 120)
+	m.usersTable.SetColumnWidth(2, 260)
+	m.usersTable.SetColumnWidth(3, 90)
+	m.usersTable.OnSelected = func(id widget.TableCellID) {
+		if id.Row == 0 {
+			m.usersTable.UnselectAll()
+			return
+		}
+		m.selectedRow = id.Row - 1
+	}
+	m.refresh()
+
+	addButton := widget.NewButtonWithIcon("کاربر جدید", theme.ContentAddIcon(), m.onAddUser)
+	editButton := widget.NewButtonWithIcon("ویرایش", theme.DocumentCreateIcon(), m.onEditUser)
+	resetButton := widget.NewButtonWithIcon("بازنشانی رمز", theme.ViewRefreshIcon(), m.onResetPassword)
+	toggleButton := widget.NewButtonWithIcon("فعال/غیرفعال", theme.VisibilityOffIcon(), m.onToggleDisabled)
+	accessButton := widget.NewButtonWithIcon("دسترسی واحدها", theme.ListIcon(), m.onEditAccess)
+	unlockButton := widget.NewButtonWithIcon("رفع قفل", theme.ConfirmIcon(), m.onUnlockUser)
+	deleteButton := widget.NewButtonWithIcon("حذف", theme.DeleteIcon(), m.onDeleteUser)
+	buttons := container.NewHBox(addButton, editButton, accessButton, resetButton, toggleButton, unlockButton, deleteButton)
+
+	m.dialog = dialog.NewCustom("مدیریت کاربران", "بستن", trackActivity(container.NewBorder(buttons, nil, nil, nil, m.usersTable)), parent)
+	m.dialog.Resize(fyne.NewSize(700, 500))
+	m.dialog.Show()
+}
+
+func (m *userManagerDialog) refresh() {
+	m.users = auth.ListUsers()
+	m.selectedRow = -1
+	m.usersTable.UnselectAll()
+	m.usersTable.Refresh()
+}
+
+func (m *userManagerDialog) updateCell(id widget.TableCellID, template fyne.CanvasObject) {
+	label := template.(*widget.Label)
+	if id.Row == 0 {
+		headers := []string{"نام کاربری", "نقش", "دپارتمان", "وضعیت"}
+		label.SetText(headers[id.Col])
+		label.TextStyle = fyne.TextStyle{Bold: true}
+		label.Refresh()
+		return
+	}
+	label.TextStyle = fyne.TextStyle{}
+	if id.Row-1 >= len(m.users) {
+		label.SetText("")
+		return
+	}
+	u := m.users[id.Row-1]
+	switch id.Col {
+	case 0:
+		label.SetText(u.Username)
+	case 1:
+		label.SetText(auth.RoleDisplayName(u.Role))
+	case 2:
+		if len(u.DepartmentShiftAccess) > 0 {
+			label.SetText(fmt.Sprintf("%d واحد-شیفت (فهرست دسترسی)", len(u.DepartmentShiftAccess)))
+		} else {
+			label.SetText(u.Department)
+		}
+	case 3:
+		if u.Disabled {
+			label.SetText("غیرفعال")
+		} else if _, locked := auth.LockedUntil(u.Username); locked {
+			label.SetText("قفل شده")
+		} else if u.MustChangePassword {
+			label.SetText("تغییر رمز")
+		} else {
+			label.SetText("فعال")
+		}
+	}
+}
+
+func (m *userManagerDialog) selectedUser() (core.User, bool) {
+	if m.selectedRow < 0 || m.selectedRow >= len(m.users) {
+		dialog.ShowInformation("انتخاب کاربر", "ابتدا یک کاربر را از جدول انتخاب کنید.", m.parentWindow)
+		return core.User{}, false
+	}
+	return m.users[m.selectedRow], true
+}
+
+func (m *userManagerDialog) showResult(err error, successMessage string) {
+	if err != nil {
+		dialog.ShowError(err, m.parentWindow)
+		return
+	}
+	m.refresh()
+	dialog.ShowInformation("مدیریت کاربران", successMessage, m.parentWindow)
+}
+
+func newRoleAndDepartmentSelects(role, department string) (*widget.Select, *widget.Select) {
+	roles := auth.RoleNames()
+	roleOptions := make([]string, len(roles))
+	for i, r := range roles {
+		roleOptions[i] = auth.RoleDisplayName(r)
+	}
+	roleSelect := widget.NewSelect(roleOptions, nil)
+	deptSelect := widget.NewSelect(core.UserDepartmentOptions(), nil)
+	roleSelect.OnChanged = func(selected string) {
+		if auth.RoleHasCapability(roleByDisplayName(selected), auth.CapAllDepartments) {
+			deptSelect.SetSelected(core.AllDepartments)
+			deptSelect.Disable()
+		} else {
+			deptSelect.Enable()
+		}
+	}
+	deptSelect.SetSelected(department)
+	roleSelect.SetSelected(auth.RoleDisplayName(role))
+	return roleSelect, deptSelect
+}
+
+func newPolicyLabel() *widget.Label {
+	label := widget.NewLabel(auth.PasswordPolicyDescription + "\nکاربر در ورود بعدی ملزم به تغییر این رمز است.")
+	label.Wrapping = fyne.TextWrapWord
+	return label
+}
+
+func (m *userManagerDialog) onAddUser() {
+	usernameEntry := widget.NewEntry()
+	passwordEntry := widget.NewPasswordEntry()
+	confirmEntry := widget.NewPasswordEntry()
+	roleSelect, deptSelect := newRoleAndDepartmentSelects(core.RoleDepartmentHead, "")
+
+	dialog.ShowForm("کاربر جدید", "ایجاد", "انصراف", []*widget.FormItem{
+		widget.NewFormItem("نام کاربری:", usernameEntry),
+		widget.NewFormItem("رمز عبور:", passwordEntry),
+		widget.NewFormItem("تکرار رمز عبور:", confirmEntry),
+		widget.NewFormItem("نقش:", roleSelect),
+		widget.NewFormItem("دپارتمان:", deptSelect),
+		widget.NewFormItem("", newPolicyLabel()),
+	}, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		if passwordEntry.Text != confirmEntry.Text {
+			dialog.ShowError(fmt.Errorf("رمز عبور و تکرار آن یکسان نیستند"), m.parentWindow)
+			return
+		}
+		err := auth.CreateUser(usernameEntry.Text, passwordEntry.Text, roleByDisplayName(roleSelect.Selected), deptSelect.Selected)
+		m.showResult(err, fmt.Sprintf("کاربر '%s' ایجاد شد.", usernameEntry.Text))
+	}, m.parentWindow)
+}
+
+func (m *userManagerDialog) onEditUser() {
+	user, ok := m.selectedUser()
+	if !ok {
+		return
+	}
+	roleSelect, deptSelect := newRoleAndDepartmentSelects(user.Role, user.Department)
+
+	dialog.ShowForm("ویرایش کاربر "+user.Username, "ذخیره", "انصراف", []*widget.FormItem{
+		widget.NewFormItem("نقش:", roleSelect),
+		widget.NewFormItem("دپارتمان:", deptSelect),
+	}, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		err := auth.UpdateUser(user.Username, roleByDisplayName(roleSelect.Selected), deptSelect.Selected)
+		m.showResult(err, fmt.Sprintf("اطلاعات کاربر '%s' به‌روز شد.", user.Username))
+	}, m.parentWindow)
+}
+
+func (m *userManagerDialog) onEditAccess() {
+	user, ok := m.selectedUser()
+	if !ok {
+		return
+	}
+	if auth.RoleHasCapability(user.Role, auth.CapAllDepartments) {
+		dialog.ShowInformation("دسترسی واحدها", fmt.Sprintf("نقش '%s' به همه واحدها دسترسی دارد.", auth.RoleDisplayName(user.Role)), m.parentWindow)
+		return
+	}
+	accessCheck := widget.NewCheckGroup(core.ManageableDepartments, nil)
+	accessCheck.SetSelected(user.DepartmentShiftAccess)
+	hint := widget.NewLabel(fmt.Sprintf("اگر هیچ واحدی انتخاب نشود، دسترسی بر اساس دپارتمان کاربر ('%s') تعیین می‌شود.", user.Department))
+	hint.Wrapping = fyne.TextWrapWord
+	content := container.NewBorder(hint, nil, nil, nil, container.NewVScroll(accessCheck))
+
+	d := dialog.NewCustomConfirm("دسترسی واحدهای "+user.Username, "ذخیره", "انصراف", content, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		err := auth.SetDepartmentShiftAccess(user.Username, accessCheck.Selected)
+		m.showResult(err, fmt.Sprintf("فهرست دسترسی کاربر '%s' به‌روز شد.", user.Username))
+	}, m.parentWindow)
+	d.Resize(fyne.NewSize(500, 500))
+	d.Show()
+}
+
+func (m *userManagerDialog) onResetPassword() {
+	user, ok := m.selectedUser()
+	if !ok {
+		return
+	}
+	passwordEntry := widget.NewPasswordEntry()
+	confirmEntry := widget.NewPasswordEntry()
+
+	dialog.ShowForm("بازنشانی رمز "+user.Username, "ذخیره", "انصراف", []*widget.FormItem{
+		widget.NewFormItem("رمز عبور جدید:", passwordEntry),
+		widget.NewFormItem("تکرار رمز عبور:", confirmEntry),
+		widget.NewFormItem("", newPolicyLabel()),
+	}, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		if passwordEntry.Text != confirmEntry.Text {
+			dialog.ShowError(fmt.Errorf("رمز عبور و تکرار آن یکسان نیستند"), m.parentWindow)
+			return
+		}
+		err := auth.ResetPassword(user.Username, passwordEntry.Text)
+		m.showResult(err, fmt.Sprintf("رمز عبور کاربر '%s' بازنشانی شد.", user.Username))
+	}, m.parentWindow)
+}
+
+func (m *userManagerDialog) onToggleDisabled() {
+	user, ok := m.selectedUser()
+	if !ok {
+		return
+	}
+	if !user.Disabled && user.Username == m.currentUser {
+		dialog.ShowError(fmt.Errorf("نمی‌توانید حساب کاربری خودتان را غیرفعال کنید"), m.parentWindow)
+		return
+	}
+	action := "غیرفعال"
+	if user.Disabled {
+		action = "فعال"
+	}
+	dialog.ShowConfirm(action+" کردن کاربر", fmt.Sprintf("آیا از %s کردن کاربر '%s' مطمئن هستید؟", action, user.Username), func(confirm bool) {
+		if !confirm {
+			return
+		}
+		err := auth.SetUserDisabled(user.Username, !user.Disabled)
+		m.showResult(err, fmt.Sprintf("کاربر '%s' %s شد.", user.Username, action))
+	}, m.parentWindow)
+}
+
+func (m *userManagerDialog) onUnlockUser() {
+	user, ok := m.selectedUser()
+	if !ok {
+		return
+	}
+	lockedUntil, locked := auth.LockedUntil(user.Username)
+	if !locked {
+		dialog.ShowInformation("رفع قفل", fmt.Sprintf("حساب '%s' قفل نیست.", user.Username), m.parentWindow)
+		return
+	}
+	message := fmt.Sprintf("حساب '%s' به دلیل تلاش‌های ناموفق ورود تا %s قفل است. قفل برداشته شود؟", user.Username, core.FormatJalaliDateTime(lockedUntil))
+	dialog.ShowConfirm("رفع قفل", message, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		err := auth.UnlockUser(user.Username)
+		m.showResult(err, fmt.Sprintf("قفل حساب '%s' برداشته شد.", user.Username))
+	}, m.parentWindow)
+}
+
+func (m *userManagerDialog) onDeleteUser() {
+	user, ok := m.selectedUser()
+	if !ok {
+		return
+	}
+	if user.Username == m.currentUser {
+		dialog.ShowError(fmt.Errorf("نمی‌توانید حساب کاربری خودتان را حذف کنید"), m.parentWindow)
+		return
+	}
+	dialog.ShowConfirm("حذف کاربر", fmt.Sprintf("آیا از حذف کاربر '%s' مطمئن هستید؟ این عمل قابل بازگشت نیست.", user.Username), func(confirm bool) {
+		if !confirm {
+			return
+		}
+		err := auth.DeleteUser(user.Username)
+		m.showResult(err, fmt.Sprintf("کاربر '%s' حذف شد.", user.Username))
+	}, m.parentWindow)
+}
@@ -0,0 +1,73 @@
+package gui
+
+import (
+	"fmt"
+
+	"overtime_go/excel"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ShowValidationReportDialog هشدارها و خطاهای خواندن فایل اکسل را با شیت، سلول و ردیف هر مورد نمایش می‌دهد.
+// اگر onContinue داده شود، پس از تأیید کاربر برای اعمال داده‌ها فراخوانی می‌شود؛ در غیر این صورت دیالوگ فقط نمایشی است.
+func ShowValidationReportDialog(parent fyne.Window, title string, report *excel.Report, onContinue func()) {
+	errorCount, warningCount := report.Counts()
+	headers := []string{"سطح", "شیت", "محل", "توضیح"}
+	table := widget.NewTable(
+		func() (int, int) { return len(report.Issues) + 1, len(headers) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			if id.Row == 0 {
+				label.TextStyle = fyne.TextStyle{Bold: true}
+				label.SetText(headers[id.Col])
+				return
+			}
+			label.TextStyle = fyne.TextStyle{}
+			issue := report.Issues[id.Row-1]
+			switch id.Col {
+			case 0:
+				label.SetText(issue.Severity.String())
+			case 1:
+				label.SetText(issue.Sheet)
+			case 2:
+				switch {
+				case issue.Cell != "":
+					label.SetText(issue.Cell)
+				case issue.Row > 0:
+					label.SetText(fmt.Sprintf("ردیف %d", issue.Row))
+				default:
+					label.SetText("")
+				}
+			case 3:
+				label.SetText(issue.Message)
+			}
+		},
+	)
+	table.SetColumnWidth(0, 60)
+	table.SetColumnWidth(1, 120)
+	table.SetColumnWidth(2, 80)
+	table.SetColumnWidth(3, 620)
+
+	summary := fmt.Sprintf("%d خطا و %d هشدار در خواندن فایل اکسل یافت شد.", errorCount, warningCount)
+	if onContinue != nil && errorCount > 0 {
+		summary += "\nردیف‌ها و مقادیر دارای خطا وارد نمی‌شوند یا صفر در نظر گرفته می‌شوند."
+	}
+	content := trackActivity(container.NewBorder(widget.NewLabel(summary), nil, nil, nil, table))
+
+	var d dialog.Dialog
+	if onContinue == nil {
+		d = dialog.NewCustom(title, "بستن", content, parent)
+	} else {
+		d = dialog.NewCustomConfirm(title, "ادامه و اعمال", "انصراف", content, func(confirm bool) {
+			if confirm {
+				onContinue()
+			}
+		}, parent)
+	}
+	d.Resize(fyne.NewSize(950, 500))
+	d.Show()
+}
@@ -0,0 +1,204 @@
+package history
+
+import (
+	"sort"
+
+	"overtime_go/core"
+)
+
+// EmployeeMonth ساعات اضافه کاری یک نفر در یک دوره است.
+type EmployeeMonth struct {
+	Period          core.Period
+	DepartmentShift string
+	Hours           int
+}
+
+// EmployeeHistory سابقه ماهانه یک نفر است که با Employee.ID شناسایی می‌شود.
+type EmployeeHistory struct {
+	ID     string
+	Name   string // آخرین نام ثبت شده
+	Months []EmployeeMonth
+}
+
+// HoursIn ساعات فرد در یک دوره را برمی‌گرداند (جمع همه واحدها)؛ ok برای دوره بدون داده false است.
+func (h EmployeeHistory) HoursIn(period core.Period) (hours int, ok bool) {
+	for _, m := range h.Months {
+		if m.Period == period {
+			hours += m.Hours
+			ok = true
+		}
+	}
+	return hours, ok
+}
+
+// RecentPeriods n دوره منتهی به until (شامل خود آن) را به ترتیب زمانی برمی‌گرداند.
+func RecentPeriods(until core.Period, n int) []core.Period {
+	if n <= 0 {
+		return nil
+	}
+	periods := make([]core.Period, n)
+	for i := 0; i < n; i++ {
+		periods[i] = until.AddMonths(i - n + 1)
+	}
+	return periods
+}
+
+// BuildEmployeeHistories از داده‌های بایگانی شده، سابقه هر نفر را در دوره‌های داده شده می‌سازد.
+// خروجی بر اساس نام و سپس کد پرسنلی مرتب شده است.
+func BuildEmployeeHistories(records []core.DepartmentData, periods []core.Period) []EmployeeHistory {
+	wanted := make(map[core.Period]bool, len(periods))
+	for _, p := range periods {
+		wanted[p] = true
+	}
+
+	byID := make(map[string]*EmployeeHistory)
+	latestNamePeriod := make(map[string]core.Period)
+	for _, data := range records {
+		if !wanted[data.Period] {
+			continue
+		}
+		for _, emp := range data.Employees {
+			if emp.ID == "" {
+				continue
+			}
+			h, ok := byID[emp.ID]
+			if !ok {
+				h = &EmployeeHistory{ID: emp.ID}
+				byID[emp.ID] = h
+			}
+			if h.Name == "" || !data.Period.Before(latestNamePeriod[emp.ID]) {
+				h.Name = emp.Name
+				latestNamePeriod[emp.ID] = data.Period
+			}
+			h.Months = append(h.Months, EmployeeMonth{
+				Period:          data.Period,
+				DepartmentShift: data.DepartmentShiftName,
+				Hours:           emp.Hours,
+			})
+		}
+	}
+
+	histories := make([]EmployeeHistory, 0, len(byID))
+	for _, h := range byID {
+		sort.SliceStable(h.Months, func(i, j int) bool { return h.Months[i].Period.Before(h.Months[j].Period) })
+		histories = append(histories, *h)
+	}
+	sort.Slice(histories, func(i, j int) bool {
+		if histories[i].Name != histories[j].Name {
+			return histories[i].Name < histories[j].Name
+		}
+		return histories[i].ID < histories[j].ID
+	})
+	return histories
+}
+
+// JumpThreshold معیار تشخیص افزایش ناگهانی ساعات است. هر دو شرط باید برقرار باشند.
+type JumpThreshold struct {
+	MinIncreaseHours   int     // حداقل افزایش مطلق (ساعت)
+	MinIncreasePercent float64 // حداقل افزایش نسبی نسبت به دوره قبل (درصد)
+}
+
+// DefaultJumpThreshold معیار پیش‌فرض: حداقل 20 ساعت و 50 درصد افزایش.
+var DefaultJumpThreshold = JumpThreshold{MinIncreaseHours: 20, MinIncreasePercent: 50}
+
+// Jump یک افزایش ناگهانی ساعات یک نفر بین دو دوره متوالی است.
+type Jump struct {
+	EmployeeID string
+	Name       string
+	From, To   core.Period
+	FromHours  int
+	ToHours    int
+}
+
+// DetectJumps افرادی را که ساعاتشان بین دو دوره متوالی از periods به شدت افزایش یافته برمی‌گرداند.
+// دوره‌ای که فرد در آن داده ندارد صفر در نظر گرفته نمی‌شود و مقایسه از آن عبور نمی‌کند.
+func DetectJumps(histories []EmployeeHistory, periods []core.Period, threshold JumpThreshold) []Jump {
+	var jumps []Jump
+	for _, h := range histories {
+		for i := 1; i < len(periods); i++ {
+			prevHours, prevOK := h.HoursIn(periods[i-1])
+			curHours, curOK := h.HoursIn(periods[i])
+			if !prevOK || !curOK {
+				continue
+			}
+			increase := curHours - prevHours
+			if increase < threshold.MinIncreaseHours || increase <= 0 {
+				continue
+			}
+			if prevHours > 0 && float64(increase)*100/float64(prevHours) < threshold.MinIncreasePercent {
+				continue
+			}
+			jumps = append(jumps, Jump{
+				EmployeeID: h.ID,
+				Name:       h.Name,
+				From:       periods[i-1],
+				To:         periods[i],
+				FromHours:  prevHours,
+				ToHours:    curHours,
+			})
+		}
+	}
+	return jumps
+}
+
+// DepartmentSummary خلاصه تخصیص یک واحد در یک دوره برای مقایسه بین واحدها است.
+type DepartmentSummary struct {
+	DepartmentShift string
+	Period          core.Period
+	EmployeeCount   int
+	Budget          int // سرانه
+	Allocated       int // مجموع ساعات تخصیص یافته
+	MaxHours        int // بیشترین ساعت یک نفر
+}
+
+// AverageHours میانگین ساعات هر نفر را برمی‌گرداند.
+func (s DepartmentSummary) AverageHours() float64 {
+	if s.EmployeeCount == 0 {
+		return 0
+	}
+	return float64(s.Allocated) / float64(s.EmployeeCount)
+}
+
+// SummarizeDepartment خلاصه تخصیص یک واحد را برمی‌گرداند.
+func SummarizeDepartment(data core.DepartmentData) DepartmentSummary {
+	summary := DepartmentSummary{
+		DepartmentShift: data.DepartmentShiftName,
+		Period:          data.Period,
+		EmployeeCount:   len(data.Employees),
+		Budget:          data.TotalHours,
+	}
+	for _, emp := range data.Employees {
+		summary.Allocated += emp.Hours
+		if emp.Hours > summary.MaxHours {
+			summary.MaxHours = emp.Hours
+		}
+	}
+	return summary
+}
+
+// CompareDepartments خلاصه همه واحدهای بایگانی شده در یک دوره را به ترتیب نام واحد برمی‌گرداند.
+func CompareDepartments(records []core.DepartmentData, period core.Period) []DepartmentSummary {
+	var summaries []DepartmentSummary
+	for _, data := range records {
+		if data.Period != period {
+			continue
+		}
+		summaries = append(summaries, SummarizeDepartment(data))
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].DepartmentShift < summaries[j].DepartmentShift })
+	return summaries
+}
+
+// AggregateSummaries مجموع چند خلاصه واحد (تعداد پرسنل، سرانه و ساعات تخصیص یافته) و بیشترین ساعت یک نفر را برمی‌گرداند.
+func AggregateSummaries(summaries []DepartmentSummary) DepartmentSummary {
+	var total DepartmentSummary
+	for _, s := range summaries {
+		total.EmployeeCount += s.EmployeeCount
+		total.Budget += s.Budget
+		total.Allocated += s.Allocated
+		if s.MaxHours > total.MaxHours {
+			total.MaxHours = s.MaxHours
+		}
+	}
+	return total
+}
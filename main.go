@@ -4,6 +4,7 @@ import (
 	"fmt"
 	// "path/filepath" // دیگر نیازی به این در main نیست چون GetExecutableDir منتقل شد
 
+	"overtime_go/audit"
 	"overtime_go/auth"
 	"overtime_go/config"
 	"overtime_go/core"
@@ -41,10 +42,25 @@ func main() {
 		fyneApp.SetIcon(fyne.NewStaticResource("app_icon.png", resources.AppIconData))
 	}
 
-	auth.InitializeDefaultUsers()
+	if err := core.InitializeOrgStructure(resources.DefaultOrgStructureJSON); err != nil {
+		fyne.LogError("Failed to load organization structure", err)
+	}
+	if err := core.InitializeColumnHeaders(resources.DefaultColumnHeadersJSON); err != nil {
+		fyne.LogError("Failed to load column headers", err)
+	}
+	if err := auth.InitializeRoles(resources.DefaultRolesJSON); err != nil {
+		fyne.LogError("Failed to load roles", err)
+	}
+	if err := auth.InitializeDefaultUsers(); err != nil {
+		fyne.LogError("Failed to load user store", err)
+	}
 	// InitializeDefaultCloudLinks نیاز به GetExecutableDir ندارد چون فایل JSON از embed خوانده می‌شود
 	// و فایل cloud_links.json قابل ویرایش توسط کاربر، مسیرش توسط cloud.LoadCloudLinks مدیریت می‌شود.
 	core.InitializeDefaultCloudLinks(resources.DefaultCloudLinksJSON)
+	audit.InitializeHeadStore(audit.HeadStore{
+		Load: func() (int, string) { return config.LoadAuditHead(fyneApp) },
+		Save: func(seq int, hash string) { config.SaveAuditHead(fyneApp, seq, hash) },
+	})
 
 	showLoginScreen()
 	fyneApp.Run()
@@ -106,6 +122,7 @@ func showMainScreen() {
 
 func performLogout() {
 	fmt.Println("Performing logout...")
+	gui.EndSession()
 	currentUser = nil
 	if mainWindow != nil {
 		mainWindow.Hide()
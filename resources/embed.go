@@ -3,13 +3,31 @@ package resources
 import _ "embed"
 
 // فایل Fara-Light.otf باید در همین پوشه (resources) موجود باشد
+//
 //go:embed Fara-Light.otf
 var FaraFontData []byte
 
 // فایل enterprise.ico باید در همین پوشه (resources) موجود باشد
+//
 //go:embed enterprise.ico
 var AppIconData []byte
 
 // فایل default_cloud_links.json باید در همین پوشه (resources) موجود باشد
+//
 //go:embed default_cloud_links.json
 var DefaultCloudLinksJSON []byte
+
+// فایل default_roles.json (نقش‌ها و توانمندی‌های پیش‌فرض) باید در همین پوشه (resources) موجود باشد
+//
+//go:embed default_roles.json
+var DefaultRolesJSON []byte
+
+// فایل default_org_structure.json (واحدها، شیفت‌ها و گروه‌های ویژه پیش‌فرض) باید در همین پوشه (resources) موجود باشد
+//
+//go:embed default_org_structure.json
+var DefaultOrgStructureJSON []byte
+
+// فایل default_column_headers.json (عناوین قابل قبول هدر ستون‌های فایل پرسنل) باید در همین پوشه (resources) موجود باشد
+//
+//go:embed default_column_headers.json
+var DefaultColumnHeadersJSON []byte
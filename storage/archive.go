@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"overtime_go/core"
+)
+
+const archiveDirName = "archive"
+
+// ArchivedDepartment یک تخصیص نهایی شده (خروجی گرفته شده) از یک واحد در یک دوره است.
+type ArchivedDepartment struct {
+	FinalizedAt time.Time           `json:"finalized_at"`
+	FinalizedBy string              `json:"finalized_by"`
+	Data        core.DepartmentData `json:"data"`
+}
+
+func archiveDir() (string, error) {
+	dataDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, archiveDirName), nil
+}
+
+// ArchiveDepartmentData تخصیص نهایی یک واحد را در بایگانی دوره آن ثبت می‌کند.
+// برای هر واحد-شیفت در هر دوره فقط آخرین نسخه نهایی نگه داشته می‌شود.
+func ArchiveDepartmentData(data *core.DepartmentData, finalizedBy string) error {
+	if data == nil || data.DepartmentShiftName == "" {
+		return fmt.Errorf("داده واحد برای بایگانی مشخص نیست")
+	}
+	if data.Period.IsZero() {
+		return fmt.Errorf("دوره واحد '%s' مشخص نیست و قابل بایگانی نیست", data.DepartmentShiftName)
+	}
+	dir, err := archiveDir()
+	if err != nil {
+		return err
+	}
+
+	record := ArchivedDepartment{
+		FinalizedAt: time.Now(),
+		FinalizedBy: finalizedBy,
+		Data:        *data,
+	}
+	fileData, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("خطا در تبدیل داده‌های بایگانی واحد '%s' به JSON: %w", data.DepartmentShiftName, err)
+	}
+	filePath := filepath.Join(dir, data.Period.Key(), sanitizeFileName(data.DepartmentShiftName)+".json")
+	if err := writeFileAtomic(filePath, fileData); err != nil {
+		return fmt.Errorf("خطا در بایگانی داده‌های واحد '%s' در '%s': %w", data.DepartmentShiftName, filePath, err)
+	}
+	return nil
+}
+
+// LoadArchive همه تخصیص‌های بایگانی شده را به ترتیب دوره و نام واحد برمی‌گرداند.
+func LoadArchive() ([]ArchivedDepartment, error) {
+	var records []ArchivedDepartment
+	dir, err := archiveDir()
+	if err != nil {
+		return records, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if err != nil {
+		return records, fmt.Errorf("خطا در جستجوی فایل‌های بایگانی: %w", err)
+	}
+	for _, filePath := range files {
+		fileData, err := os.ReadFile(filePath)
+		if err != nil {
+			fmt.Printf("هشدار: خطا در خواندن فایل بایگانی '%s': %v\n", filePath, err)
+			continue
+		}
+		var record ArchivedDepartment
+		if err := json.Unmarshal(fileData, &record); err != nil {
+			fmt.Printf("هشدار: خطا در پارس کردن فایل بایگانی '%s': %v\n", filePath, err)
+			continue
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Data.Period != records[j].Data.Period {
+			return records[i].Data.Period.Before(records[j].Data.Period)
+		}
+		return records[i].Data.DepartmentShiftName < records[j].Data.DepartmentShiftName
+	})
+	return records, nil
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"overtime_go/core"
+	"overtime_go/utils"
+)
+
+const (
+	appDataDirName     = "OvertimeApp"
+	allocationsDirName = "allocations"
+	noMonthDirName     = "بدون_ماه"
+)
+
+// departmentRecord قالب فایل JSON ذخیره شده برای هر واحد-شیفت در هر ماه است.
+type departmentRecord struct {
+	SavedAt time.Time           `json:"saved_at"`
+	SavedBy string              `json:"saved_by"`
+	Data    core.DepartmentData `json:"data"`
+}
+
+// DataDir مسیر پوشه داده‌های برنامه در پوشه تنظیمات کاربر را برمی‌گرداند (و در صورت نیاز می‌سازد).
+// اگر پوشه تنظیمات کاربر در دسترس نباشد، پوشه کنار فایل اجرایی استفاده می‌شود.
+func DataDir() (string, error) {
+	baseDir, err := os.UserConfigDir()
+	if err != nil {
+		fmt.Printf("هشدار: پوشه تنظیمات کاربر یافت نشد (%v). استفاده از پوشه فایل اجرایی.\n", err)
+		baseDir, err = utils.GetExecutableDir()
+		if err != nil {
+			return "", fmt.Errorf("خطا در تعیین پوشه ذخیره‌سازی: %w", err)
+		}
+	}
+	dir := filepath.Join(baseDir, appDataDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("خطا در ایجاد پوشه ذخیره‌سازی '%s': %w", dir, err)
+	}
+	return dir, nil
+}
+
+// sanitizeFileName نویسه‌هایی که در نام فایل مجاز نیستند را جایگزین می‌کند.
+func sanitizeFileName(name string) string {
+	replacer := strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
+	return replacer.Replace(strings.TrimSpace(name))
+}
+
+func periodDirName(data *core.DepartmentData) string {
+	if data.Period.IsZero() {
+		return noMonthDirName
+	}
+	return data.Period.Key()
+}
+
+func allocationsDir() (string, error) {
+	dataDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, allocationsDirName), nil
+}
+
+// writeFileAtomic فایل را ابتدا در یک فایل موقت نوشته و سپس جایگزین می‌کند تا فایل نیمه‌کاره باقی نماند.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp_*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// SaveDepartmentData داده‌های یک واحد را با کلید واحد-شیفت و دوره (سال و ماه) ذخیره می‌کند و زمان ذخیره را برمی‌گرداند.
+func SaveDepartmentData(data *core.DepartmentData, savedBy string) (time.Time, error) {
+	if data == nil || data.DepartmentShiftName == "" {
+		return time.Time{}, fmt.Errorf("داده واحد برای ذخیره مشخص نیست")
+	}
+	dir, err := allocationsDir()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	record := departmentRecord{
+		SavedAt: time.Now(),
+		SavedBy: savedBy,
+		Data:    *data,
+	}
+	fileData, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("خطا در تبدیل داده‌های واحد '%s' به JSON: %w", data.DepartmentShiftName, err)
+	}
+
+	filePath := filepath.Join(dir, periodDirName(data), sanitizeFileName(data.DepartmentShiftName)+".json")
+	if err := writeFileAtomic(filePath, fileData); err != nil {
+		return time.Time{}, fmt.Errorf("خطا در ذخیره داده‌های واحد '%s' در '%s': %w", data.DepartmentShiftName, filePath, err)
+	}
+	return record.SavedAt, nil
+}
+
+// SavedDepartment آخرین داده ذخیره شده یک واحد به همراه زمان ذخیره آن است.
+type SavedDepartment struct {
+	Data    *core.DepartmentData
+	SavedAt time.Time
+	SavedBy string
+}
+
+// LoadLatestDepartments برای هر واحد-شیفت، جدیدترین داده ذخیره شده (در بین همه ماه‌ها) را برمی‌گرداند.
+func LoadLatestDepartments() (map[string]SavedDepartment, error) {
+	result := make(map[string]SavedDepartment)
+	dir, err := allocationsDir()
+	if err != nil {
+		return result, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if err != nil {
+		return result, fmt.Errorf("خطا در جستجوی فایل‌های ذخیره شده: %w", err)
+	}
+	for _, filePath := range files {
+		fileData, err := os.ReadFile(filePath)
+		if err != nil {
+			fmt.Printf("هشدار: خطا در خواندن فایل ذخیره شده '%s': %v\n", filePath, err)
+			continue
+		}
+		var record departmentRecord
+		if err := json.Unmarshal(fileData, &record); err != nil {
+			fmt.Printf("هشدار: خطا در پارس کردن فایل ذخیره شده '%s': %v\n", filePath, err)
+			continue
+		}
+		deptShift := record.Data.DepartmentShiftName
+		if deptShift == "" {
+			continue
+		}
+		if existing, ok := result[deptShift]; ok && !record.SavedAt.After(existing.SavedAt) {
+			continue
+		}
+		data := record.Data
+		result[deptShift] = SavedDepartment{Data: &data, SavedAt: record.SavedAt, SavedBy: record.SavedBy}
+	}
+	return result, nil
+}